@@ -10,15 +10,36 @@ import (
 
 func RunServer() {
 
-	// Create a server with a single tool.
-	server := mcp.NewServer(&mcp.Implementation{Name: "MongoDB MCP", Version: "v1.0.0"}, nil)
-
-	// Create a tool and add it to the server.
 	coreTools := tools.NewTool()
+	resources := coreTools.NewMongoDBCollectionResources()
+
+	// Create a server notifying the subscribers of the collection resources.
+	server := mcp.NewServer(&mcp.Implementation{Name: "MongoDB MCP", Version: "v1.0.0"}, &mcp.ServerOptions{
+		SubscribeHandler:   resources.Subscribe,
+		UnsubscribeHandler: resources.Unsubscribe,
+	})
+	// Report classified errors instead of the raw driver errors.
+	server.AddReceivingMiddleware(tools.TranslateToolErrors)
+
+	// Add the tools to the server.
 	coreTools.NewMongoDBListCollectionsTool().AttachTool(server)
 	coreTools.NewMongoDBCountDocumentsTool().AttachTool(server)
 	coreTools.NewMongoDBFindOneTool().AttachTool(server)
 	coreTools.NewMongoDBFindTool().AttachTool(server)
+	coreTools.NewMongoDBCollectionSchemaTool().AttachTool(server)
+	coreTools.NewMongoDBScanPIITool().AttachTool(server)
+	coreTools.NewMongoDBListIndexesTool().AttachTool(server)
+	coreTools.NewMongoDBExplainTool().AttachTool(server)
+	coreTools.NewMongoDBRecommendIndexesTool().AttachTool(server)
+	coreTools.NewMongoDBCollectionStatsTool().AttachTool(server)
+	coreTools.NewMongoDBDatabaseStatsTool().AttachTool(server)
+	coreTools.NewMongoDBIndexUsageTool().AttachTool(server)
+	coreTools.NewMongoDBWatchChangesTool().AttachTool(server)
+	coreTools.NewMongoDBQuotaStatusTool().AttachTool(server)
+	// Transaction tools
+	coreTools.NewMongoDBBeginTransactionTool().AttachTool(server)
+	coreTools.NewMongoDBCommitTransactionTool().AttachTool(server)
+	coreTools.NewMongoDBAbortTransactionTool().AttachTool(server)
 	if !coreTools.ReadOnly {
 		// Insert tools
 		coreTools.NewMongoDBInsertOneTool().AttachTool(server)
@@ -26,12 +47,15 @@ func RunServer() {
 		// Update tools
 		coreTools.NewMongoDBFindOneAndUpdateTool().AttachTool(server)
 		coreTools.NewMongoDBFindOneAndReplaceTool().AttachTool(server)
+		coreTools.NewMongoDBReplaceOneTool().AttachTool(server)
 		coreTools.NewMongoDBUpdateOneTool().AttachTool(server)
 		coreTools.NewMongoDBUpdateManyTool().AttachTool(server)
 		// Delete tools
 		coreTools.NewMongoDBDeleteOneTool().AttachTool(server)
 		coreTools.NewMongoDBDeleteManyTool().AttachTool(server)
 		coreTools.NewMongoDBFindOneAndDeleteTool().AttachTool(server)
+		// Bulk write tool
+		coreTools.NewMongoDBBulkWriteTool().AttachTool(server)
 	}
 
 	if coreTools.AllowAggregates {
@@ -39,6 +63,15 @@ func RunServer() {
 		coreTools.NewMongoDBAggregateTool().AttachTool(server)
 	}
 
+	if coreTools.AllowAdmin {
+		// Index management tools
+		coreTools.NewMongoDBCreateIndexTool().AttachTool(server)
+		coreTools.NewMongoDBDropIndexTool().AttachTool(server)
+	}
+
+	// Collection context resources
+	resources.AttachResources(server)
+
 	// Run the server over stdin/stdout, until the client disconnects.
 	if err := server.Run(context.Background(), &mcp.StdioTransport{}); err != nil {
 		log.Fatal(err)
@@ -0,0 +1,72 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type MongoDBAbortTransactionToolInput struct {
+	TransactionID string `json:"transaction_id" jsonschema:"The id of the transaction returned by the Begin Transaction tool"`
+}
+
+type MongoDBAbortTransactionToolOutput struct {
+	Aborted bool `json:"aborted" jsonschema:"Whether the transaction was aborted"`
+}
+
+type NewMongoDBAbortTransactionTool struct {
+	tool *Tool
+}
+
+func (t *Tool) NewMongoDBAbortTransactionTool() *NewMongoDBAbortTransactionTool {
+	return &NewMongoDBAbortTransactionTool{
+		tool: t,
+	}
+}
+
+func (t *NewMongoDBAbortTransactionTool) name() string {
+	return "[MongoDB] Abort Transaction Tool"
+}
+
+func (t *NewMongoDBAbortTransactionTool) description() string {
+	return "# Abort a transaction in MongoDB.\n\n" +
+		"This tool aborts a transaction started with the Begin Transaction tool, discarding its writes, and ends it.\n\n"
+}
+
+func (t *NewMongoDBAbortTransactionTool) toolCall(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	input MongoDBAbortTransactionToolInput,
+) (
+	*mcp.CallToolResult,
+	MongoDBAbortTransactionToolOutput,
+	error,
+) {
+	defResponse := MongoDBAbortTransactionToolOutput{
+		Aborted: false,
+	}
+
+	done, err := t.tool.quotas.acquire(req, operationWrite)
+	if err != nil {
+		return nil, defResponse, err
+	}
+	defer done()
+
+	ctx, cancel := t.tool.timeouts.withTimeout(ctx, operationWrite, nil)
+	defer cancel()
+
+	if err := t.tool.transactions.abort(ctx, input.TransactionID, req.Session); err != nil {
+		return nil, defResponse, err
+	}
+
+	return nil, MongoDBAbortTransactionToolOutput{
+		Aborted: true,
+	}, nil
+}
+
+func (t *NewMongoDBAbortTransactionTool) AttachTool(server *mcp.Server) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        t.name(),
+		Description: t.description(),
+	}, t.toolCall)
+}
@@ -9,11 +9,14 @@ import (
 )
 
 type MongoDBAggregateToolInput struct {
-	DatabaseName   *string  `json:"database_name,omitempty" jsonschema:"Optional name of the database to find the document in"`
-	CollectionName string   `json:"collection_name" jsonschema:"Name of the collection to find the document in"`
-	Pipeline       []bson.M `json:"pipeline" jsonschema:"The aggregation pipeline to apply to the collection"`
-	AllowDiskUse   *bool    `json:"allow_disk_use,omitempty" jsonschema:"Optional flag to allow disk use for the aggregation operation"`
-	BatchSize      *int32   `json:"batch_size,omitempty" jsonschema:"Optional batch size for the aggregation operation"`
+	DatabaseName        *string  `json:"database_name,omitempty" jsonschema:"Optional name of the database to find the document in"`
+	CollectionName      string   `json:"collection_name" jsonschema:"Name of the collection to find the document in"`
+	Pipeline            []bson.M `json:"pipeline" jsonschema:"The aggregation pipeline to apply to the collection"`
+	AllowDiskUse        *bool    `json:"allow_disk_use,omitempty" jsonschema:"Optional flag to allow disk use for the aggregation operation"`
+	BatchSize           *int32   `json:"batch_size,omitempty" jsonschema:"Optional batch size for the aggregation operation"`
+	AllowCollectionScan *bool    `json:"allow_collection_scan,omitempty" jsonschema:"Optional confirmation to run the operation even if the query cost guard detects a collection scan, only honoured when the server asks for confirmation"`
+	TransactionID       *string  `json:"transaction_id,omitempty" jsonschema:"Optional id of a transaction returned by the Begin Transaction tool to run the operation in"`
+	MaxTimeMillis       *int64   `json:"max_time_ms,omitempty" jsonschema:"Optional time limit of the operation in milliseconds, defaults to the server timeout of the operation and is capped by the server maximum"`
 }
 
 type MongoDBAggregateToolOutput struct {
@@ -39,6 +42,19 @@ func (t *NewMongoDBAggregateTool) description() string {
 		"This tool can be used to perform aggregation operations on a MongoDB collection.\n\n"
 }
 
+// writesResult reports whether the pipeline writes its result to a collection.
+func writesResult(pipeline []bson.M) bool {
+	for _, stage := range pipeline {
+		if _, ok := stage["$out"]; ok {
+			return true
+		}
+		if _, ok := stage["$merge"]; ok {
+			return true
+		}
+	}
+	return false
+}
+
 func (t *NewMongoDBAggregateTool) toolCall(
 	ctx context.Context,
 	req *mcp.CallToolRequest,
@@ -52,6 +68,19 @@ func (t *NewMongoDBAggregateTool) toolCall(
 		Result: nil,
 	}
 
+	done, err := t.tool.quotas.acquire(req, operationAggregate)
+	if err != nil {
+		return nil, defResponse, err
+	}
+	defer done()
+
+	ctx, cancel := t.tool.timeouts.withTimeout(ctx, operationAggregate, input.MaxTimeMillis)
+	defer cancel()
+
+	if err := t.tool.pipelines.check(input.Pipeline); err != nil {
+		return nil, defResponse, err
+	}
+
 	DB, err := t.tool.Database(input.DatabaseName)
 	if err != nil {
 		return nil, defResponse, err
@@ -59,6 +88,26 @@ func (t *NewMongoDBAggregateTool) toolCall(
 
 	collection := DB.Collection(input.CollectionName)
 
+	if err := t.tool.redaction.checkPipeline(collectionNamespace(collection), input.Pipeline); err != nil {
+		return nil, defResponse, err
+	}
+
+	pipeline, err := t.tool.scope.pipeline(req.Session, DB.Name(), collection.Name(), input.Pipeline)
+	if err != nil {
+		return nil, defResponse, err
+	}
+
+	query := explainQuery{operation: "aggregate", pipeline: pipeline}
+	if err := t.tool.guard.check(ctx, collection, query, input.AllowCollectionScan); err != nil {
+		return nil, defResponse, err
+	}
+
+	ctx, release, err := t.tool.transactions.join(ctx, req.Session, input.TransactionID)
+	if err != nil {
+		return nil, defResponse, err
+	}
+	defer release()
+
 	opts := options.Aggregate()
 
 	if input.AllowDiskUse != nil && *input.AllowDiskUse {
@@ -68,18 +117,46 @@ func (t *NewMongoDBAggregateTool) toolCall(
 		opts.SetBatchSize(*input.BatchSize)
 	}
 
-	res, err := collection.Aggregate(ctx, input.Pipeline, opts)
-	if err != nil {
-		return nil, defResponse, err
-	}
+	comment := newOperationComment()
+	opts.SetComment(comment)
+	stop := t.tool.killOnCancel(ctx, commentFilter(comment))
+	defer stop()
 
+	progress := newProgressReporter(req)
 	var docs []bson.M
-	if err := res.All(ctx, &docs); err != nil {
+	aggregate := func(ctx context.Context) error {
+		res, err := collection.Aggregate(ctx, pipeline, opts)
+		if err != nil {
+			return err
+		}
+		defer res.Close(ctx)
+
+		docs = []bson.M{}
+		for res.Next(ctx) {
+			var doc bson.M
+			if err := res.Decode(&doc); err != nil {
+				return err
+			}
+			docs = append(docs, doc)
+			progress.report(ctx, int64(len(docs)), 0, "Fetching results")
+		}
+		return res.Err()
+	}
+
+	retries := 0
+	// Pipelines writing their result with $out or $merge are not retried, the
+	// first attempt may have written part of it.
+	if writesResult(input.Pipeline) {
+		err = aggregate(ctx)
+	} else {
+		retries, err = t.tool.retry.do(ctx, aggregate)
+	}
+	if err != nil {
 		return nil, defResponse, err
 	}
 
-	return nil, MongoDBAggregateToolOutput{
-		Result: docs,
+	return retryResult(retries), MongoDBAggregateToolOutput{
+		Result: t.tool.redaction.redactAll(collectionNamespace(collection), docs),
 	}, nil
 }
 
@@ -0,0 +1,73 @@
+package tools
+
+import (
+	"context"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type MongoDBBeginTransactionToolInput struct{}
+
+type MongoDBBeginTransactionToolOutput struct {
+	TransactionID string `json:"transaction_id" jsonschema:"The id of the transaction to pass as transaction_id to the read and write tools"`
+	ExpiresAt     string `json:"expires_at" jsonschema:"When the transaction is aborted if it is not committed, in RFC 3339 format"`
+}
+
+type NewMongoDBBeginTransactionTool struct {
+	tool *Tool
+}
+
+func (t *Tool) NewMongoDBBeginTransactionTool() *NewMongoDBBeginTransactionTool {
+	return &NewMongoDBBeginTransactionTool{
+		tool: t,
+	}
+}
+
+func (t *NewMongoDBBeginTransactionTool) name() string {
+	return "[MongoDB] Begin Transaction Tool"
+}
+
+func (t *NewMongoDBBeginTransactionTool) description() string {
+	return "# Begin a transaction in MongoDB.\n\n" +
+		"This tool starts a multi-document transaction and returns its id. Pass the id as transaction_id to the read " +
+		"and write tools to run their operations inside the transaction, then commit or abort it with the Commit " +
+		"Transaction and Abort Transaction tools.\n\n" +
+		"The transaction is aborted automatically when it times out or when the client disconnects. " +
+		"Transactions require a replica set or a sharded cluster.\n\n"
+}
+
+func (t *NewMongoDBBeginTransactionTool) toolCall(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	input MongoDBBeginTransactionToolInput,
+) (
+	*mcp.CallToolResult,
+	MongoDBBeginTransactionToolOutput,
+	error,
+) {
+	defResponse := MongoDBBeginTransactionToolOutput{}
+
+	done, err := t.tool.quotas.acquire(req, operationWrite)
+	if err != nil {
+		return nil, defResponse, err
+	}
+	defer done()
+
+	id, expires, err := t.tool.transactions.begin(ctx, t.tool.client, req.Session)
+	if err != nil {
+		return nil, defResponse, err
+	}
+
+	return nil, MongoDBBeginTransactionToolOutput{
+		TransactionID: id,
+		ExpiresAt:     expires.UTC().Format(time.RFC3339),
+	}, nil
+}
+
+func (t *NewMongoDBBeginTransactionTool) AttachTool(server *mcp.Server) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        t.name(),
+		Description: t.description(),
+	}, t.toolCall)
+}
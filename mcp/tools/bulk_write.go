@@ -0,0 +1,351 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// bulkWriteBatchSize is the number of operations sent at once by the Bulk
+// Write tool.
+const bulkWriteBatchSize = 1000
+
+const (
+	bulkWriteSucceeded = "succeeded"
+	bulkWriteFailed    = "failed"
+	bulkWriteSkipped   = "skipped"
+)
+
+type MongoDBBulkWriteOperation struct {
+	Type         string                 `json:"type" jsonschema:"The type of the operation, one of insertOne, updateOne, updateMany, replaceOne, deleteOne or deleteMany"`
+	Document     bson.M                 `json:"document,omitempty" jsonschema:"The document to insert, for insertOne"`
+	Filter       bson.M                 `json:"filter,omitempty" jsonschema:"The filter selecting the documents, for the update, replace and delete operations"`
+	Update       any                    `json:"update,omitempty" jsonschema:"The update to apply, for updateOne and updateMany, either a document of update operators or an aggregation pipeline given as an array of stages"`
+	Replacement  bson.M                 `json:"replacement,omitempty" jsonschema:"The replacement document, for replaceOne"`
+	Upsert       *bool                  `json:"upsert,omitempty" jsonschema:"Optional whether to insert a document when none matches, for the update and replace operations"`
+	ArrayFilters []bson.M               `json:"array_filters,omitempty" jsonschema:"Optional filters selecting the array elements updated through the $[<identifier>] positional operator, for updateOne and updateMany"`
+	Hint         *string                `json:"hint,omitempty" jsonschema:"Optional name of the index to use, for the update, replace and delete operations"`
+	Collation    *MongoDBCollationInput `json:"collation,omitempty" jsonschema:"Optional collation used to match the filter, for the update, replace and delete operations"`
+}
+
+type MongoDBBulkWriteToolInput struct {
+	DatabaseName        *string                     `json:"database_name,omitempty" jsonschema:"Optional name of the database of the collection"`
+	CollectionName      string                      `json:"collection_name" jsonschema:"Name of the collection to write to"`
+	Operations          []MongoDBBulkWriteOperation `json:"operations" jsonschema:"The write operations to execute"`
+	Ordered             *bool                       `json:"ordered,omitempty" jsonschema:"Optional whether to execute the operations in order and stop at the first error, defaults to true"`
+	AllowCollectionScan *bool                       `json:"allow_collection_scan,omitempty" jsonschema:"Optional confirmation to run the operations even if the query cost guard detects a collection scan, only honoured when the server asks for confirmation"`
+	TransactionID       *string                     `json:"transaction_id,omitempty" jsonschema:"Optional id of a transaction returned by the Begin Transaction tool to run the operations in"`
+	MaxTimeMillis       *int64                      `json:"max_time_ms,omitempty" jsonschema:"Optional time limit of the operation in milliseconds, defaults to the server timeout of the operation and is capped by the server maximum"`
+}
+
+type MongoDBBulkWriteError struct {
+	Index   int    `json:"index" jsonschema:"The index of the failed operation"`
+	Code    int    `json:"code" jsonschema:"The server error code"`
+	Message string `json:"message" jsonschema:"The error message"`
+}
+
+type MongoDBBulkWriteOperationResult struct {
+	Index      int    `json:"index" jsonschema:"The index of the operation"`
+	Type       string `json:"type" jsonschema:"The type of the operation"`
+	Status     string `json:"status" jsonschema:"One of succeeded, failed, or skipped when an ordered bulk write stopped before the operation"`
+	InsertedID any    `json:"inserted_id,omitempty" jsonschema:"The _id of the inserted document, for insertOne"`
+	UpsertedID any    `json:"upserted_id,omitempty" jsonschema:"The _id of the upserted document, for the update and replace operations"`
+}
+
+type MongoDBBulkWriteToolOutput struct {
+	InsertedCount     int64                             `json:"inserted_count" jsonschema:"The number of inserted documents"`
+	MatchedCount      int64                             `json:"matched_count" jsonschema:"The number of documents matched by the update and replace operations"`
+	ModifiedCount     int64                             `json:"modified_count" jsonschema:"The number of documents modified by the update and replace operations"`
+	DeletedCount      int64                             `json:"deleted_count" jsonschema:"The number of deleted documents"`
+	UpsertedCount     int64                             `json:"upserted_count" jsonschema:"The number of upserted documents"`
+	Operations        []MongoDBBulkWriteOperationResult `json:"operations" jsonschema:"The result of each operation, in the order of the input"`
+	WriteErrors       []MongoDBBulkWriteError           `json:"write_errors" jsonschema:"The errors of the failed operations"`
+	WriteConcernError *string                           `json:"write_concern_error,omitempty" jsonschema:"The write concern error, if the writes could not be acknowledged as requested"`
+}
+
+type NewMongoDBBulkWriteTool struct {
+	tool *Tool
+}
+
+func (t *Tool) NewMongoDBBulkWriteTool() *NewMongoDBBulkWriteTool {
+	return &NewMongoDBBulkWriteTool{
+		tool: t,
+	}
+}
+
+func (t *NewMongoDBBulkWriteTool) name() string {
+	return "[MongoDB] Bulk Write Tool"
+}
+
+func (t *NewMongoDBBulkWriteTool) description() string {
+	return "# Execute many write operations in MongoDB.\n\n" +
+		"This tool can be used to execute a list of insertOne, updateOne, updateMany, replaceOne, deleteOne and deleteMany " +
+		"operations on a MongoDB collection in a single request.\n\n" +
+		"Ordered bulk writes stop at the first failed operation, unordered bulk writes execute every operation. " +
+		"The result of each operation and the write errors are reported with the index of the operation.\n\n"
+}
+
+// writeModel converts the operation into a driver write model, returning the
+// _id of the document to insert for insertOne.
+func (o MongoDBBulkWriteOperation) writeModel(scope bson.M) (mongo.WriteModel, any, error) {
+	upsert := o.Upsert != nil && *o.Upsert
+	var hint any
+	if o.Hint != nil && *o.Hint != "" {
+		hint = *o.Hint
+	}
+
+	switch o.Type {
+	case "insertOne":
+		if o.Document == nil {
+			return nil, nil, fmt.Errorf("document is required")
+		}
+		// Assign the _id here, the driver does not report the generated ids
+		// of a bulk write.
+		document := bson.M{}
+		for key, value := range o.Document {
+			document[key] = value
+		}
+		if _, ok := document["_id"]; !ok {
+			document["_id"] = bson.NewObjectID()
+		}
+		if err := scopeDocument(scope, document, "document"); err != nil {
+			return nil, nil, err
+		}
+		return mongo.NewInsertOneModel().SetDocument(document), document["_id"], nil
+	case "updateOne", "updateMany":
+		if o.Filter == nil || o.Update == nil {
+			return nil, nil, fmt.Errorf("filter and update are required")
+		}
+		update, err := updateDocument(o.Update)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := scopeUpdate(scope, update, "update"); err != nil {
+			return nil, nil, err
+		}
+		if upsert {
+			if err := scopeUpsert(scope, o.Filter, "filter"); err != nil {
+				return nil, nil, err
+			}
+		}
+		if o.Type == "updateOne" {
+			model := mongo.NewUpdateOneModel().SetFilter(scopedFilter(scope, o.Filter)).SetUpdate(update).SetUpsert(upsert).SetCollation(o.Collation.options())
+			if len(o.ArrayFilters) > 0 {
+				model.SetArrayFilters(arrayFilters(o.ArrayFilters))
+			}
+			if hint != nil {
+				model.SetHint(hint)
+			}
+			return model, nil, nil
+		}
+		model := mongo.NewUpdateManyModel().SetFilter(scopedFilter(scope, o.Filter)).SetUpdate(update).SetUpsert(upsert).SetCollation(o.Collation.options())
+		if len(o.ArrayFilters) > 0 {
+			model.SetArrayFilters(arrayFilters(o.ArrayFilters))
+		}
+		if hint != nil {
+			model.SetHint(hint)
+		}
+		return model, nil, nil
+	case "replaceOne":
+		if o.Filter == nil || o.Replacement == nil {
+			return nil, nil, fmt.Errorf("filter and replacement are required")
+		}
+		if err := scopeDocument(scope, o.Replacement, "replacement"); err != nil {
+			return nil, nil, err
+		}
+		model := mongo.NewReplaceOneModel().SetFilter(scopedFilter(scope, o.Filter)).SetReplacement(o.Replacement).SetUpsert(upsert).SetCollation(o.Collation.options())
+		if hint != nil {
+			model.SetHint(hint)
+		}
+		return model, nil, nil
+	case "deleteOne":
+		if o.Filter == nil {
+			return nil, nil, fmt.Errorf("filter is required")
+		}
+		model := mongo.NewDeleteOneModel().SetFilter(scopedFilter(scope, o.Filter)).SetCollation(o.Collation.options())
+		if hint != nil {
+			model.SetHint(hint)
+		}
+		return model, nil, nil
+	case "deleteMany":
+		if o.Filter == nil {
+			return nil, nil, fmt.Errorf("filter is required")
+		}
+		model := mongo.NewDeleteManyModel().SetFilter(scopedFilter(scope, o.Filter)).SetCollation(o.Collation.options())
+		if hint != nil {
+			model.SetHint(hint)
+		}
+		return model, nil, nil
+	}
+
+	return nil, nil, fmt.Errorf("unknown type %q, use insertOne, updateOne, updateMany, replaceOne, deleteOne or deleteMany", o.Type)
+}
+
+func (t *NewMongoDBBulkWriteTool) toolCall(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	input MongoDBBulkWriteToolInput,
+) (
+	*mcp.CallToolResult,
+	MongoDBBulkWriteToolOutput,
+	error,
+) {
+	defResponse := MongoDBBulkWriteToolOutput{
+		Operations:  []MongoDBBulkWriteOperationResult{},
+		WriteErrors: []MongoDBBulkWriteError{},
+	}
+
+	done, err := t.tool.quotas.acquire(req, operationWrite)
+	if err != nil {
+		return nil, defResponse, err
+	}
+	defer done()
+
+	ctx, cancel := t.tool.timeouts.withTimeout(ctx, operationWrite, input.MaxTimeMillis)
+	defer cancel()
+
+	if len(input.Operations) == 0 {
+		return nil, defResponse, fmt.Errorf("At least one operation is required")
+	}
+
+	DB, err := t.tool.Database(input.DatabaseName)
+	if err != nil {
+		return nil, defResponse, err
+	}
+
+	collection := DB.Collection(input.CollectionName)
+
+	scope, err := t.tool.scope.resolve(req.Session, collectionNamespace(collection))
+	if err != nil {
+		return nil, defResponse, err
+	}
+
+	models := make([]mongo.WriteModel, 0, len(input.Operations))
+	insertedIDs := make([]any, len(input.Operations))
+	for i, operation := range input.Operations {
+		if err := t.tool.filters.check(operation.Filter, fmt.Sprintf("operations.%d.filter", i)); err != nil {
+			return nil, defResponse, err
+		}
+		if err := t.tool.pipelines.checkUpdate(operation.Update, fmt.Sprintf("operations.%d.update", i)); err != nil {
+			return nil, defResponse, err
+		}
+		model, insertedID, err := operation.writeModel(scope)
+		if err != nil {
+			return nil, defResponse, fmt.Errorf("Invalid operation %d: %w", i, err)
+		}
+		models = append(models, model)
+		insertedIDs[i] = insertedID
+
+		if err := t.tool.redaction.checkQuery(collectionNamespace(collection), operation.Filter, nil); err != nil {
+			return nil, defResponse, fmt.Errorf("Operation %d: %w", i, err)
+		}
+
+		query := explainQuery{operation: "find", filter: scopedFilter(scope, operation.Filter)}
+		switch operation.Type {
+		case "insertOne":
+			continue
+		case "updateOne", "replaceOne", "deleteOne":
+			query.limit = 1
+		}
+		if err := t.tool.guard.check(ctx, collection, query, input.AllowCollectionScan); err != nil {
+			return nil, defResponse, fmt.Errorf("Operation %d: %w", i, err)
+		}
+	}
+
+	ctx, release, err := t.tool.transactions.join(ctx, req.Session, input.TransactionID)
+	if err != nil {
+		return nil, defResponse, err
+	}
+	defer release()
+
+	ordered := input.Ordered == nil || *input.Ordered
+	comment := newOperationComment()
+	opts := options.BulkWrite().SetOrdered(ordered).SetComment(comment)
+
+	stop := t.tool.killOnCancel(ctx, commentFilter(comment))
+	defer stop()
+
+	progress := newProgressReporter(req)
+	output := defResponse
+	failed := map[int]bool{}
+	firstFailure := len(input.Operations)
+	upsertedIDs := map[int]any{}
+
+	// The operations are sent in batches to report the progress of large
+	// bulk writes.
+	for start := 0; start < len(models); start += bulkWriteBatchSize {
+		end := min(start+bulkWriteBatchSize, len(models))
+
+		res, err := collection.BulkWrite(ctx, models[start:end], opts)
+		var bulkErr mongo.BulkWriteException
+		if err != nil && !errors.As(err, &bulkErr) {
+			if start > 0 {
+				return nil, defResponse, fmt.Errorf("The operations from %d on failed, the operations before were executed: %w", start, err)
+			}
+			return nil, defResponse, err
+		}
+
+		if res != nil {
+			output.InsertedCount += res.InsertedCount
+			output.MatchedCount += res.MatchedCount
+			output.ModifiedCount += res.ModifiedCount
+			output.DeletedCount += res.DeletedCount
+			output.UpsertedCount += res.UpsertedCount
+			for index, id := range res.UpsertedIDs {
+				upsertedIDs[start+int(index)] = id
+			}
+		}
+
+		for _, writeErr := range bulkErr.WriteErrors {
+			index := start + writeErr.Index
+			failed[index] = true
+			firstFailure = min(firstFailure, index)
+			output.WriteErrors = append(output.WriteErrors, MongoDBBulkWriteError{
+				Index:   index,
+				Code:    writeErr.Code,
+				Message: writeErr.Message,
+			})
+		}
+		if bulkErr.WriteConcernError != nil {
+			message := bulkErr.WriteConcernError.Error()
+			output.WriteConcernError = &message
+		}
+
+		progress.report(ctx, int64(end), int64(len(models)), "Executing operations")
+		if ordered && len(bulkErr.WriteErrors) > 0 {
+			break
+		}
+	}
+
+	for i, operation := range input.Operations {
+		result := MongoDBBulkWriteOperationResult{
+			Index:  i,
+			Type:   operation.Type,
+			Status: bulkWriteSucceeded,
+		}
+		switch {
+		case failed[i]:
+			result.Status = bulkWriteFailed
+		case ordered && i > firstFailure:
+			result.Status = bulkWriteSkipped
+		default:
+			result.InsertedID = insertedIDs[i]
+			result.UpsertedID = upsertedIDs[i]
+		}
+		output.Operations = append(output.Operations, result)
+	}
+
+	return nil, output, nil
+}
+
+func (t *NewMongoDBBulkWriteTool) AttachTool(server *mcp.Server) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        t.name(),
+		Description: t.description(),
+	}, t.toolCall)
+}
@@ -0,0 +1,33 @@
+package tools
+
+import (
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type MongoDBCollationInput struct {
+	Locale          string `json:"locale" jsonschema:"The ICU locale, e.g. en or fr_CA, use simple for binary comparison"`
+	CaseLevel       bool   `json:"case_level,omitempty" jsonschema:"Optional whether to include case comparison at strength 1 or 2"`
+	CaseFirst       string `json:"case_first,omitempty" jsonschema:"Optional sort order of case differences, one of upper, lower or off"`
+	Strength        int    `json:"strength,omitempty" jsonschema:"Optional level of comparison to perform, from 1 to 5, defaults to 3"`
+	NumericOrdering bool   `json:"numeric_ordering,omitempty" jsonschema:"Optional whether to compare numeric strings as numbers"`
+	Alternate       string `json:"alternate,omitempty" jsonschema:"Optional whether whitespace and punctuation are considered base characters, one of non-ignorable or shifted"`
+	MaxVariable     string `json:"max_variable,omitempty" jsonschema:"Optional characters considered ignorable when alternate is shifted, one of punct or space"`
+	Backwards       bool   `json:"backwards,omitempty" jsonschema:"Optional whether strings with diacritics sort from the back of the string"`
+}
+
+func (c *MongoDBCollationInput) options() *options.Collation {
+	if c == nil {
+		return nil
+	}
+
+	return &options.Collation{
+		Locale:          c.Locale,
+		CaseLevel:       c.CaseLevel,
+		CaseFirst:       c.CaseFirst,
+		Strength:        c.Strength,
+		NumericOrdering: c.NumericOrdering,
+		Alternate:       c.Alternate,
+		MaxVariable:     c.MaxVariable,
+		Backwards:       c.Backwards,
+	}
+}
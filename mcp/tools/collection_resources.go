@@ -0,0 +1,190 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+const collectionResourceScheme = "mongodb"
+
+type MongoDBCollectionResources struct {
+	tool   *Tool
+	server *mcp.Server
+
+	mu            sync.Mutex
+	subscriptions map[string]*changeSubscription
+	sessions      map[*mcp.ServerSession]bool
+}
+
+func (t *Tool) NewMongoDBCollectionResources() *MongoDBCollectionResources {
+	return &MongoDBCollectionResources{
+		tool:          t,
+		subscriptions: map[string]*changeSubscription{},
+		sessions:      map[*mcp.ServerSession]bool{},
+	}
+}
+
+// parseCollectionURI splits a mongodb://<database>/<collection>/<kind> URI
+// into its parts.
+func parseCollectionURI(uri string) (database string, collection string, kind string, err error) {
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		return "", "", "", mcp.ResourceNotFoundError(uri)
+	}
+
+	path := strings.Trim(parsed.Path, "/")
+	separator := strings.LastIndex(path, "/")
+	if parsed.Scheme != collectionResourceScheme || parsed.Host == "" || separator <= 0 {
+		return "", "", "", mcp.ResourceNotFoundError(uri)
+	}
+
+	collection, err = url.PathUnescape(path[:separator])
+	if err != nil {
+		return "", "", "", mcp.ResourceNotFoundError(uri)
+	}
+
+	return parsed.Host, collection, path[separator+1:], nil
+}
+
+func (r *MongoDBCollectionResources) collection(uri string) (*mongo.Collection, error) {
+	database, collection, _, err := parseCollectionURI(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	DB, err := r.tool.Database(&database)
+	if err != nil {
+		return nil, err
+	}
+
+	return DB.Collection(collection), nil
+}
+
+func (r *MongoDBCollectionResources) result(uri string, value any) (*mcp.ReadResourceResult, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{{
+			URI:      uri,
+			MIMEType: "application/json",
+			Text:     string(data),
+		}},
+	}, nil
+}
+
+func (r *MongoDBCollectionResources) readSchema(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	ctx, cancel := r.tool.timeouts.withTimeout(ctx, operationRead, nil)
+	defer cancel()
+
+	collection, err := r.collection(req.Params.URI)
+	if err != nil {
+		return nil, err
+	}
+
+	namespace := collection.Database().Name() + "." + collection.Name()
+	scope, err := r.tool.scope.resolve(req.Session, namespace)
+	if err != nil {
+		return nil, err
+	}
+	// The schemas of the sessions with a scope of their own are not cached,
+	// the cache is shared by every session.
+	scopeKey, err := r.tool.scope.key(req.Session, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	schema, ok := r.tool.schemaCache.get(namespace)
+	if !ok || scopeKey != "" {
+		schema, err = inferCollectionSchema(ctx, collection, scope, defaultSchemaSampleSize)
+		if err != nil {
+			return nil, err
+		}
+		if scopeKey == "" {
+			r.tool.schemaCache.set(namespace, schema)
+		}
+	}
+
+	return r.result(req.Params.URI, r.tool.redaction.redactSchema(namespace, schema))
+}
+
+func (r *MongoDBCollectionResources) readIndexes(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	ctx, cancel := r.tool.timeouts.withTimeout(ctx, operationRead, nil)
+	defer cancel()
+
+	collection, err := r.collection(req.Params.URI)
+	if err != nil {
+		return nil, err
+	}
+
+	indexes, err := listIndexes(ctx, collection)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.result(req.Params.URI, indexes)
+}
+
+func (r *MongoDBCollectionResources) readStats(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	ctx, cancel := r.tool.timeouts.withTimeout(ctx, operationRead, nil)
+	defer cancel()
+
+	collection, err := r.collection(req.Params.URI)
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := collectionStats(ctx, collection)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.result(req.Params.URI, stats)
+}
+
+// AttachResources registers the resource templates on the server. The server
+// must be created with Subscribe and Unsubscribe as its subscription handlers
+// to notify the subscribers of the resources.
+func (r *MongoDBCollectionResources) AttachResources(server *mcp.Server) {
+	r.server = server
+
+	server.AddResourceTemplate(&mcp.ResourceTemplate{
+		Name:        "collection-schema",
+		Title:       "[MongoDB] Collection Schema",
+		Description: "The schema of a MongoDB collection, inferred from sampled documents and cached for a limited time.",
+		MIMEType:    "application/json",
+		URITemplate: collectionResourceScheme + "://{database}/{collection}/schema",
+	}, r.readSchema)
+
+	server.AddResourceTemplate(&mcp.ResourceTemplate{
+		Name:        "collection-indexes",
+		Title:       "[MongoDB] Collection Indexes",
+		Description: "The indexes defined on a MongoDB collection.",
+		MIMEType:    "application/json",
+		URITemplate: collectionResourceScheme + "://{database}/{collection}/indexes",
+	}, r.readIndexes)
+
+	server.AddResourceTemplate(&mcp.ResourceTemplate{
+		Name:        "collection-stats",
+		Title:       "[MongoDB] Collection Stats",
+		Description: "The storage statistics and document count of a MongoDB collection.",
+		MIMEType:    "application/json",
+		URITemplate: collectionResourceScheme + "://{database}/{collection}/stats",
+	}, r.readStats)
+
+	server.AddResourceTemplate(&mcp.ResourceTemplate{
+		Name:        "collection-changes",
+		Title:       "[MongoDB] Collection Changes",
+		Description: "The recent change events of a subscribed MongoDB collection, optionally restricted by an extended JSON filter on the events.",
+		MIMEType:    "application/json",
+		URITemplate: collectionResourceScheme + "://{database}/{collection}/changes{?filter}",
+	}, r.readChanges)
+}
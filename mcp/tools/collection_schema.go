@@ -0,0 +1,96 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type MongoDBCollectionSchemaToolInput struct {
+	DatabaseName   *string `json:"database_name,omitempty" jsonschema:"Optional name of the database of the collection"`
+	CollectionName string  `json:"collection_name" jsonschema:"Name of the collection to infer the schema of"`
+	SampleSize     *int64  `json:"sample_size,omitempty" jsonschema:"Optional number of documents to sample, defaults to 100 and is capped at 1000"`
+	MaxTimeMillis  *int64  `json:"max_time_ms,omitempty" jsonschema:"Optional time limit of the operation in milliseconds, defaults to the server timeout of the operation and is capped by the server maximum"`
+}
+
+type MongoDBCollectionSchemaToolOutput struct {
+	Schema *MongoDBCollectionSchema `json:"schema" jsonschema:"The schema inferred from the sampled documents"`
+}
+
+type NewMongoDBCollectionSchemaTool struct {
+	tool *Tool
+}
+
+func (t *Tool) NewMongoDBCollectionSchemaTool() *NewMongoDBCollectionSchemaTool {
+	return &NewMongoDBCollectionSchemaTool{
+		tool: t,
+	}
+}
+
+func (t *NewMongoDBCollectionSchemaTool) name() string {
+	return "[MongoDB] Collection Schema Tool"
+}
+
+func (t *NewMongoDBCollectionSchemaTool) description() string {
+	return "# Infer the schema of a MongoDB collection.\n\n" +
+		"This tool samples documents from a MongoDB collection and infers the fields, " +
+		"their types, type frequencies, presence ratio, array element types and example values.\n\n" +
+		"Use it before writing filters to get the field names and types right. " +
+		"The schema is also returned as a JSON Schema document.\n\n"
+}
+
+func (t *NewMongoDBCollectionSchemaTool) toolCall(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	input MongoDBCollectionSchemaToolInput,
+) (
+	*mcp.CallToolResult,
+	MongoDBCollectionSchemaToolOutput,
+	error,
+) {
+	defResponse := MongoDBCollectionSchemaToolOutput{
+		Schema: nil,
+	}
+
+	done, err := t.tool.quotas.acquire(req, operationRead)
+	if err != nil {
+		return nil, defResponse, err
+	}
+	defer done()
+
+	ctx, cancel := t.tool.timeouts.withTimeout(ctx, operationRead, input.MaxTimeMillis)
+	defer cancel()
+
+	DB, err := t.tool.Database(input.DatabaseName)
+	if err != nil {
+		return nil, defResponse, err
+	}
+
+	collection := DB.Collection(input.CollectionName)
+
+	var sampleSize int64 = defaultSchemaSampleSize
+	if input.SampleSize != nil && *input.SampleSize > 0 {
+		sampleSize = min(*input.SampleSize, maxSchemaSampleSize)
+	}
+
+	scope, err := t.tool.scope.resolve(req.Session, collectionNamespace(collection))
+	if err != nil {
+		return nil, defResponse, err
+	}
+
+	schema, err := inferCollectionSchema(ctx, collection, scope, sampleSize)
+	if err != nil {
+		return nil, defResponse, err
+	}
+
+	return nil, MongoDBCollectionSchemaToolOutput{
+		Schema: t.tool.redaction.redactSchema(collectionNamespace(collection), schema),
+	}, nil
+}
+
+func (t *NewMongoDBCollectionSchemaTool) AttachTool(server *mcp.Server) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        t.name(),
+		Description: t.description(),
+	}, t.toolCall)
+}
@@ -0,0 +1,83 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type MongoDBCollectionStatsToolInput struct {
+	DatabaseName   *string `json:"database_name,omitempty" jsonschema:"Optional name of the database of the collection"`
+	CollectionName string  `json:"collection_name" jsonschema:"Name of the collection to get the statistics of"`
+	MaxTimeMillis  *int64  `json:"max_time_ms,omitempty" jsonschema:"Optional time limit of the operation in milliseconds, defaults to the server timeout of the operation and is capped by the server maximum"`
+}
+
+type MongoDBCollectionStatsToolOutput struct {
+	Stats *MongoDBCollectionStats `json:"stats" jsonschema:"The storage statistics of the collection"`
+}
+
+type NewMongoDBCollectionStatsTool struct {
+	tool *Tool
+}
+
+func (t *Tool) NewMongoDBCollectionStatsTool() *NewMongoDBCollectionStatsTool {
+	return &NewMongoDBCollectionStatsTool{
+		tool: t,
+	}
+}
+
+func (t *NewMongoDBCollectionStatsTool) name() string {
+	return "[MongoDB] Collection Stats Tool"
+}
+
+func (t *NewMongoDBCollectionStatsTool) description() string {
+	return "# Get the statistics of a MongoDB collection.\n\n" +
+		"This tool can be used to get the document count, data and storage size, average document size " +
+		"and the size of each index of a MongoDB collection.\n\n"
+}
+
+func (t *NewMongoDBCollectionStatsTool) toolCall(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	input MongoDBCollectionStatsToolInput,
+) (
+	*mcp.CallToolResult,
+	MongoDBCollectionStatsToolOutput,
+	error,
+) {
+	defResponse := MongoDBCollectionStatsToolOutput{
+		Stats: nil,
+	}
+
+	done, err := t.tool.quotas.acquire(req, operationRead)
+	if err != nil {
+		return nil, defResponse, err
+	}
+	defer done()
+
+	ctx, cancel := t.tool.timeouts.withTimeout(ctx, operationRead, input.MaxTimeMillis)
+	defer cancel()
+
+	DB, err := t.tool.Database(input.DatabaseName)
+	if err != nil {
+		return nil, defResponse, err
+	}
+
+	collection := DB.Collection(input.CollectionName)
+
+	stats, err := collectionStats(ctx, collection)
+	if err != nil {
+		return nil, defResponse, err
+	}
+
+	return nil, MongoDBCollectionStatsToolOutput{
+		Stats: stats,
+	}, nil
+}
+
+func (t *NewMongoDBCollectionStatsTool) AttachTool(server *mcp.Server) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        t.name(),
+		Description: t.description(),
+	}, t.toolCall)
+}
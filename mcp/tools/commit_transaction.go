@@ -0,0 +1,73 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type MongoDBCommitTransactionToolInput struct {
+	TransactionID string `json:"transaction_id" jsonschema:"The id of the transaction returned by the Begin Transaction tool"`
+}
+
+type MongoDBCommitTransactionToolOutput struct {
+	Committed bool `json:"committed" jsonschema:"Whether the transaction was committed"`
+}
+
+type NewMongoDBCommitTransactionTool struct {
+	tool *Tool
+}
+
+func (t *Tool) NewMongoDBCommitTransactionTool() *NewMongoDBCommitTransactionTool {
+	return &NewMongoDBCommitTransactionTool{
+		tool: t,
+	}
+}
+
+func (t *NewMongoDBCommitTransactionTool) name() string {
+	return "[MongoDB] Commit Transaction Tool"
+}
+
+func (t *NewMongoDBCommitTransactionTool) description() string {
+	return "# Commit a transaction in MongoDB.\n\n" +
+		"This tool commits a transaction started with the Begin Transaction tool, making its writes visible to other " +
+		"operations, and ends it.\n\n"
+}
+
+func (t *NewMongoDBCommitTransactionTool) toolCall(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	input MongoDBCommitTransactionToolInput,
+) (
+	*mcp.CallToolResult,
+	MongoDBCommitTransactionToolOutput,
+	error,
+) {
+	defResponse := MongoDBCommitTransactionToolOutput{
+		Committed: false,
+	}
+
+	done, err := t.tool.quotas.acquire(req, operationWrite)
+	if err != nil {
+		return nil, defResponse, err
+	}
+	defer done()
+
+	ctx, cancel := t.tool.timeouts.withTimeout(ctx, operationWrite, nil)
+	defer cancel()
+
+	if err := t.tool.transactions.commit(ctx, input.TransactionID, req.Session); err != nil {
+		return nil, defResponse, err
+	}
+
+	return nil, MongoDBCommitTransactionToolOutput{
+		Committed: true,
+	}, nil
+}
+
+func (t *NewMongoDBCommitTransactionTool) AttachTool(server *mcp.Server) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        t.name(),
+		Description: t.description(),
+	}, t.toolCall)
+}
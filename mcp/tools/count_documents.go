@@ -9,11 +9,14 @@ import (
 )
 
 type MongoDBCountDocumentsToolInput struct {
-	DatabaseName   *string `json:"database_name,omitempty" jsonschema:"Optional name of the database to find the document in"`
-	CollectionName string  `json:"collection_name" jsonschema:"Name of the collection to find the document in"`
-	Filter         bson.M  `json:"filter" jsonschema:"The filter to find the document with"`
-	Skip           *int64  `json:"skip,omitempty" jsonschema:"Optional number of documents to skip"`
-	Limit          *int64  `json:"limit,omitempty" jsonschema:"Optional maximum number of documents to return, defaults to 10"`
+	DatabaseName        *string `json:"database_name,omitempty" jsonschema:"Optional name of the database to find the document in"`
+	CollectionName      string  `json:"collection_name" jsonschema:"Name of the collection to find the document in"`
+	Filter              bson.M  `json:"filter" jsonschema:"The filter to find the document with"`
+	Skip                *int64  `json:"skip,omitempty" jsonschema:"Optional number of documents to skip"`
+	Limit               *int64  `json:"limit,omitempty" jsonschema:"Optional maximum number of documents to return, defaults to 10"`
+	AllowCollectionScan *bool   `json:"allow_collection_scan,omitempty" jsonschema:"Optional confirmation to run the operation even if the query cost guard detects a collection scan, only honoured when the server asks for confirmation"`
+	TransactionID       *string `json:"transaction_id,omitempty" jsonschema:"Optional id of a transaction returned by the Begin Transaction tool to run the operation in"`
+	MaxTimeMillis       *int64  `json:"max_time_ms,omitempty" jsonschema:"Optional time limit of the operation in milliseconds, defaults to the server timeout of the operation and is capped by the server maximum"`
 }
 
 type MongoDBCountDocumentsToolOutput struct {
@@ -52,6 +55,19 @@ func (t *NewMongoDBCountDocumentsTool) toolCall(
 		Count: 0,
 	}
 
+	done, err := t.tool.quotas.acquire(req, operationRead)
+	if err != nil {
+		return nil, defResponse, err
+	}
+	defer done()
+
+	ctx, cancel := t.tool.timeouts.withTimeout(ctx, operationRead, input.MaxTimeMillis)
+	defer cancel()
+
+	if err := t.tool.filters.check(input.Filter, "filter"); err != nil {
+		return nil, defResponse, err
+	}
+
 	DB, err := t.tool.Database(input.DatabaseName)
 	if err != nil {
 		return nil, defResponse, err
@@ -59,6 +75,16 @@ func (t *NewMongoDBCountDocumentsTool) toolCall(
 
 	collection := DB.Collection(input.CollectionName)
 
+	if err := t.tool.redaction.checkQuery(collectionNamespace(collection), input.Filter, nil); err != nil {
+		return nil, defResponse, err
+	}
+
+	scope, err := t.tool.scope.resolve(req.Session, collectionNamespace(collection))
+	if err != nil {
+		return nil, defResponse, err
+	}
+	filter := scopedFilter(scope, input.Filter)
+
 	var limit int64 = 10
 	if input.Limit != nil && *input.Limit > 0 {
 		limit = *input.Limit
@@ -68,14 +94,30 @@ func (t *NewMongoDBCountDocumentsTool) toolCall(
 		skip = *input.Skip
 	}
 
+	query := explainQuery{operation: "count", filter: filter, limit: limit, skip: skip}
+	if err := t.tool.guard.check(ctx, collection, query, input.AllowCollectionScan); err != nil {
+		return nil, defResponse, err
+	}
+
+	ctx, release, err := t.tool.transactions.join(ctx, req.Session, input.TransactionID)
+	if err != nil {
+		return nil, defResponse, err
+	}
+	defer release()
+
 	filterOptions := options.Count().SetLimit(limit).SetSkip(skip)
 
-	total, err := collection.CountDocuments(ctx, input.Filter, filterOptions)
+	var total int64
+	retries, err := t.tool.retry.do(ctx, func(ctx context.Context) error {
+		var err error
+		total, err = collection.CountDocuments(ctx, filter, filterOptions)
+		return err
+	})
 	if err != nil {
 		return nil, defResponse, err
 	}
 
-	return nil, MongoDBCountDocumentsToolOutput{
+	return retryResult(retries), MongoDBCountDocumentsToolOutput{
 		Count: total,
 	}, nil
 }
@@ -0,0 +1,315 @@
+package tools
+
+import (
+	"context"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type MongoDBCreateIndexToolInput struct {
+	DatabaseName            *string                `json:"database_name,omitempty" jsonschema:"Optional name of the database of the collection"`
+	CollectionName          string                 `json:"collection_name" jsonschema:"Name of the collection to create the index on"`
+	Keys                    []MongoDBIndexKey      `json:"keys" jsonschema:"The ordered keys of the index, multiple keys create a compound index"`
+	Name                    *string                `json:"name,omitempty" jsonschema:"Optional name of the index, defaults to the name generated from the keys"`
+	Unique                  *bool                  `json:"unique,omitempty" jsonschema:"Optional whether the index is unique, defaults to false"`
+	Sparse                  *bool                  `json:"sparse,omitempty" jsonschema:"Optional whether the index only references documents with the indexed fields, defaults to false"`
+	Hidden                  *bool                  `json:"hidden,omitempty" jsonschema:"Optional whether the index is hidden from the query planner, defaults to false"`
+	ExpireAfterSeconds      *int32                 `json:"expire_after_seconds,omitempty" jsonschema:"Optional TTL in seconds, to create a TTL index on a date field"`
+	PartialFilterExpression bson.M                 `json:"partial_filter_expression,omitempty" jsonschema:"Optional filter of the documents to index, to create a partial index"`
+	Collation               *MongoDBCollationInput `json:"collation,omitempty" jsonschema:"Optional collation of the index"`
+	WildcardProjection      bson.M                 `json:"wildcard_projection,omitempty" jsonschema:"Optional fields to include or exclude from a wildcard index"`
+	Weights                 bson.M                 `json:"weights,omitempty" jsonschema:"Optional field weights of a text index"`
+	DefaultLanguage         *string                `json:"default_language,omitempty" jsonschema:"Optional default language of a text index"`
+	DryRun                  *bool                  `json:"dry_run,omitempty" jsonschema:"Optional whether to only report if an equivalent index already exists without creating it, defaults to false"`
+	MaxTimeMillis           *int64                 `json:"max_time_ms,omitempty" jsonschema:"Optional time limit of the operation in milliseconds, defaults to the server timeout of the operation and is capped by the server maximum"`
+}
+
+type MongoDBCreateIndexToolOutput struct {
+	Name            string            `json:"name" jsonschema:"The name of the created index"`
+	Created         bool              `json:"created" jsonschema:"Whether the index was created"`
+	DryRun          bool              `json:"dry_run" jsonschema:"Whether this was a dry run"`
+	EquivalentIndex *MongoDBIndexInfo `json:"equivalent_index,omitempty" jsonschema:"The existing index with the same keys and options, if any"`
+	ConflictIndex   *MongoDBIndexInfo `json:"conflict_index,omitempty" jsonschema:"The existing index with the same keys but different options, if any"`
+}
+
+type NewMongoDBCreateIndexTool struct {
+	tool *Tool
+}
+
+func (t *Tool) NewMongoDBCreateIndexTool() *NewMongoDBCreateIndexTool {
+	return &NewMongoDBCreateIndexTool{
+		tool: t,
+	}
+}
+
+func (t *NewMongoDBCreateIndexTool) name() string {
+	return "[MongoDB] Create Index Tool"
+}
+
+func (t *NewMongoDBCreateIndexTool) description() string {
+	return "# Create an index in MongoDB.\n\n" +
+		"This tool can be used to create a single field, compound, text, 2dsphere, wildcard, TTL or partial index " +
+		"on a MongoDB collection.\n\n" +
+		"Use `dry_run` to check whether an equivalent index already exists before creating it.\n\n"
+}
+
+// defaultCollation holds the values the server stores for the collation
+// fields left unset, e.g. {"strength": 3}.
+var defaultCollation = bson.M{
+	"caseLevel":       false,
+	"caseFirst":       "off",
+	"strength":        int32(3),
+	"numericOrdering": false,
+	"alternate":       "non-ignorable",
+	"maxVariable":     "punct",
+	"backwards":       false,
+}
+
+// sameCollation reports whether the collation stored with an index is the
+// collation of the input, the unset fields taking their default values.
+func sameCollation(input *MongoDBCollationInput, collation bson.M) bool {
+	// The simple collation is the binary comparison of the indexes without
+	// collation.
+	if input == nil || input.Locale == "simple" {
+		return collation == nil || collation["locale"] == "simple"
+	}
+	if collation == nil || input.Locale != collation["locale"] {
+		return false
+	}
+
+	fields := bson.M{
+		"caseLevel":       input.CaseLevel,
+		"caseFirst":       input.CaseFirst,
+		"strength":        input.Strength,
+		"numericOrdering": input.NumericOrdering,
+		"alternate":       input.Alternate,
+		"maxVariable":     input.MaxVariable,
+		"backwards":       input.Backwards,
+	}
+	for field, value := range fields {
+		if value == "" || value == 0 {
+			value = defaultCollation[field]
+		}
+		stored, ok := collation[field]
+		if !ok {
+			stored = defaultCollation[field]
+		}
+		if !sameIndexValue(value, stored) {
+			return false
+		}
+	}
+	return true
+}
+
+// sameIndexOptions reports whether the existing index has the options of the
+// index described by the input.
+func (t *NewMongoDBCreateIndexTool) sameIndexOptions(input MongoDBCreateIndexToolInput, index MongoDBIndexInfo) bool {
+	if (input.Unique != nil && *input.Unique) != index.Unique {
+		return false
+	}
+	if (input.Sparse != nil && *input.Sparse) != index.Sparse {
+		return false
+	}
+	if (input.ExpireAfterSeconds == nil) != (index.ExpireAfterSeconds == nil) {
+		return false
+	}
+	if input.ExpireAfterSeconds != nil && int64(*input.ExpireAfterSeconds) != *index.ExpireAfterSeconds {
+		return false
+	}
+	if (len(input.PartialFilterExpression) != 0 || len(index.PartialFilterExpression) != 0) &&
+		!sameIndexValue(input.PartialFilterExpression, index.PartialFilterExpression) {
+		return false
+	}
+	if !sameCollation(input.Collation, index.Collation) {
+		return false
+	}
+	if (len(input.WildcardProjection) != 0 || len(index.WildcardProjection) != 0) &&
+		!sameIndexValue(input.WildcardProjection, index.WildcardProjection) {
+		return false
+	}
+
+	// The text fields have a weight of 1 and the english language unless
+	// set otherwise.
+	weights := bson.M{}
+	for _, key := range input.Keys {
+		if key.Value == "text" {
+			weights[key.Field] = 1
+		}
+	}
+	if len(weights) == 0 {
+		return len(index.Weights) == 0
+	}
+	for field, weight := range input.Weights {
+		weights[field] = weight
+	}
+	if !sameIndexValue(weights, index.Weights) {
+		return false
+	}
+	language := "english"
+	if input.DefaultLanguage != nil && *input.DefaultLanguage != "" {
+		language = *input.DefaultLanguage
+	}
+	return language == index.DefaultLanguage
+}
+
+// watchIndexBuild reports the progress of the index build matching the
+// currentOp filter until done is closed.
+func (t *NewMongoDBCreateIndexTool) watchIndexBuild(ctx context.Context, build bson.D, progress *progressReporter, done <-chan struct{}) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		operations, err := t.tool.currentOperations(ctx, build)
+		if err != nil {
+			continue
+		}
+		for _, operation := range operations {
+			state, ok := indexDocumentValue(operation["progress"])
+			if !ok {
+				continue
+			}
+			processed, _ := indexNumber(state["done"])
+			total, _ := indexNumber(state["total"])
+			message, _ := operation["msg"].(string)
+			progress.report(ctx, int64(processed), int64(total), message)
+			break
+		}
+	}
+}
+
+func (t *NewMongoDBCreateIndexTool) toolCall(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	input MongoDBCreateIndexToolInput,
+) (
+	*mcp.CallToolResult,
+	MongoDBCreateIndexToolOutput,
+	error,
+) {
+	defResponse := MongoDBCreateIndexToolOutput{
+		Created: false,
+	}
+
+	done, err := t.tool.quotas.acquire(req, operationAdmin)
+	if err != nil {
+		return nil, defResponse, err
+	}
+	defer done()
+
+	ctx, cancel := t.tool.timeouts.withTimeout(ctx, operationAdmin, input.MaxTimeMillis)
+	defer cancel()
+
+	DB, err := t.tool.Database(input.DatabaseName)
+	if err != nil {
+		return nil, defResponse, err
+	}
+
+	collection := DB.Collection(input.CollectionName)
+
+	keys, err := indexKeysDocument(input.Keys)
+	if err != nil {
+		return nil, defResponse, err
+	}
+
+	indexes, err := listIndexes(ctx, collection)
+	if err != nil {
+		return nil, defResponse, err
+	}
+
+	output := MongoDBCreateIndexToolOutput{
+		DryRun: input.DryRun != nil && *input.DryRun,
+	}
+	for _, index := range indexes {
+		if !sameIndexKeys(index.Keys, storedIndexKeys(input.Keys)) {
+			continue
+		}
+		if t.sameIndexOptions(input, index) {
+			output.EquivalentIndex = &index
+			output.Name = index.Name
+		} else {
+			output.ConflictIndex = &index
+		}
+	}
+
+	if output.DryRun || output.EquivalentIndex != nil {
+		return nil, output, nil
+	}
+
+	// The name identifies the index build in currentOp.
+	name := defaultIndexName(keys)
+	if input.Name != nil && *input.Name != "" {
+		name = *input.Name
+	}
+	opts := options.Index().SetName(name)
+	if input.Unique != nil && *input.Unique {
+		opts.SetUnique(*input.Unique)
+	}
+	if input.Sparse != nil && *input.Sparse {
+		opts.SetSparse(*input.Sparse)
+	}
+	if input.Hidden != nil && *input.Hidden {
+		opts.SetHidden(*input.Hidden)
+	}
+	if input.ExpireAfterSeconds != nil {
+		opts.SetExpireAfterSeconds(*input.ExpireAfterSeconds)
+	}
+	if len(input.PartialFilterExpression) > 0 {
+		opts.SetPartialFilterExpression(input.PartialFilterExpression)
+	}
+	if input.Collation != nil {
+		opts.SetCollation(input.Collation.options())
+	}
+	if len(input.WildcardProjection) > 0 {
+		opts.SetWildcardProjection(input.WildcardProjection)
+	}
+	if len(input.Weights) > 0 {
+		opts.SetWeights(input.Weights)
+	}
+	if input.DefaultLanguage != nil && *input.DefaultLanguage != "" {
+		opts.SetDefaultLanguage(*input.DefaultLanguage)
+	}
+
+	build := bson.D{
+		{Key: "ns", Value: collection.Database().Name() + "." + collection.Name()},
+		{Key: "command.createIndexes", Value: collection.Name()},
+		{Key: "command.indexes.name", Value: name},
+	}
+	stop := t.tool.killOnCancel(ctx, build)
+	defer stop()
+
+	built := make(chan struct{})
+	go t.watchIndexBuild(ctx, build, newProgressReporter(req), built)
+
+	name, err = collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    keys,
+		Options: opts,
+	})
+	close(built)
+	if err != nil {
+		return nil, defResponse, err
+	}
+
+	output.Name = name
+	output.Created = true
+
+	return nil, output, nil
+}
+
+func (t *NewMongoDBCreateIndexTool) AttachTool(server *mcp.Server) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        t.name(),
+		Description: t.description(),
+	}, t.toolCall)
+}
@@ -0,0 +1,80 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type MongoDBDatabaseStatsToolInput struct {
+	DatabaseName  *string `json:"database_name,omitempty" jsonschema:"Optional name of the database to get the statistics of"`
+	MaxTimeMillis *int64  `json:"max_time_ms,omitempty" jsonschema:"Optional time limit of the operation in milliseconds, defaults to the server timeout of the operation and is capped by the server maximum"`
+}
+
+type MongoDBDatabaseStatsToolOutput struct {
+	Stats *MongoDBDatabaseStats `json:"stats" jsonschema:"The storage statistics of the database"`
+}
+
+type NewMongoDBDatabaseStatsTool struct {
+	tool *Tool
+}
+
+func (t *Tool) NewMongoDBDatabaseStatsTool() *NewMongoDBDatabaseStatsTool {
+	return &NewMongoDBDatabaseStatsTool{
+		tool: t,
+	}
+}
+
+func (t *NewMongoDBDatabaseStatsTool) name() string {
+	return "[MongoDB] Database Stats Tool"
+}
+
+func (t *NewMongoDBDatabaseStatsTool) description() string {
+	return "# Get the statistics of a MongoDB database.\n\n" +
+		"This tool can be used to get the number of collections, views, documents and indexes, " +
+		"and the data, storage and index size of a MongoDB database.\n\n"
+}
+
+func (t *NewMongoDBDatabaseStatsTool) toolCall(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	input MongoDBDatabaseStatsToolInput,
+) (
+	*mcp.CallToolResult,
+	MongoDBDatabaseStatsToolOutput,
+	error,
+) {
+	defResponse := MongoDBDatabaseStatsToolOutput{
+		Stats: nil,
+	}
+
+	done, err := t.tool.quotas.acquire(req, operationRead)
+	if err != nil {
+		return nil, defResponse, err
+	}
+	defer done()
+
+	ctx, cancel := t.tool.timeouts.withTimeout(ctx, operationRead, input.MaxTimeMillis)
+	defer cancel()
+
+	DB, err := t.tool.Database(input.DatabaseName)
+	if err != nil {
+		return nil, defResponse, err
+	}
+
+	stats, err := databaseStats(ctx, DB)
+	if err != nil {
+		return nil, defResponse, err
+	}
+
+	return nil, MongoDBDatabaseStatsToolOutput{
+		Stats: stats,
+	}, nil
+}
+
+func (t *NewMongoDBDatabaseStatsTool) AttachTool(server *mcp.Server) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        t.name(),
+		Description: t.description(),
+	}, t.toolCall)
+}
@@ -10,9 +10,12 @@ import (
 )
 
 type MongoDBDeleteManyToolInput struct {
-	DatabaseName   *string `json:"database_name,omitempty" jsonschema:"Optional name of the database to find the document in"`
-	CollectionName string  `json:"collection_name" jsonschema:"Name of the collection to find the document in"`
-	Filter         bson.M  `json:"filter" jsonschema:"The filter to find the document with"`
+	DatabaseName        *string `json:"database_name,omitempty" jsonschema:"Optional name of the database to find the document in"`
+	CollectionName      string  `json:"collection_name" jsonschema:"Name of the collection to find the document in"`
+	Filter              bson.M  `json:"filter" jsonschema:"The filter to find the document with"`
+	AllowCollectionScan *bool   `json:"allow_collection_scan,omitempty" jsonschema:"Optional confirmation to run the operation even if the query cost guard detects a collection scan, only honoured when the server asks for confirmation"`
+	TransactionID       *string `json:"transaction_id,omitempty" jsonschema:"Optional id of a transaction returned by the Begin Transaction tool to run the operation in"`
+	MaxTimeMillis       *int64  `json:"max_time_ms,omitempty" jsonschema:"Optional time limit of the operation in milliseconds, defaults to the server timeout of the operation and is capped by the server maximum"`
 }
 
 type MongoDBDeleteManyToolOutput struct {
@@ -51,6 +54,19 @@ func (t *NewMongoDBDeleteManyTool) toolCall(
 		Result: nil,
 	}
 
+	done, err := t.tool.quotas.acquire(req, operationWrite)
+	if err != nil {
+		return nil, defResponse, err
+	}
+	defer done()
+
+	ctx, cancel := t.tool.timeouts.withTimeout(ctx, operationWrite, input.MaxTimeMillis)
+	defer cancel()
+
+	if err := t.tool.filters.check(input.Filter, "filter"); err != nil {
+		return nil, defResponse, err
+	}
+
 	DB, err := t.tool.Database(input.DatabaseName)
 	if err != nil {
 		return nil, defResponse, err
@@ -58,9 +74,35 @@ func (t *NewMongoDBDeleteManyTool) toolCall(
 
 	collection := DB.Collection(input.CollectionName)
 
+	if err := t.tool.redaction.checkQuery(collectionNamespace(collection), input.Filter, nil); err != nil {
+		return nil, defResponse, err
+	}
+
+	scope, err := t.tool.scope.resolve(req.Session, collectionNamespace(collection))
+	if err != nil {
+		return nil, defResponse, err
+	}
+	filter := scopedFilter(scope, input.Filter)
+
+	query := explainQuery{operation: "find", filter: filter}
+	if err := t.tool.guard.check(ctx, collection, query, input.AllowCollectionScan); err != nil {
+		return nil, defResponse, err
+	}
+
+	ctx, release, err := t.tool.transactions.join(ctx, req.Session, input.TransactionID)
+	if err != nil {
+		return nil, defResponse, err
+	}
+	defer release()
+
 	opts := options.DeleteMany()
 
-	res, err := collection.DeleteMany(ctx, input.Filter, opts)
+	comment := newOperationComment()
+	opts.SetComment(comment)
+	stop := t.tool.killOnCancel(ctx, commentFilter(comment))
+	defer stop()
+
+	res, err := collection.DeleteMany(ctx, filter, opts)
 	if err != nil {
 		return nil, defResponse, err
 	}
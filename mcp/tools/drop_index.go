@@ -0,0 +1,113 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type MongoDBDropIndexToolInput struct {
+	DatabaseName   *string           `json:"database_name,omitempty" jsonschema:"Optional name of the database of the collection"`
+	CollectionName string            `json:"collection_name" jsonschema:"Name of the collection to drop the index from"`
+	Name           *string           `json:"name,omitempty" jsonschema:"Optional name of the index to drop, either name or keys is required"`
+	Keys           []MongoDBIndexKey `json:"keys,omitempty" jsonschema:"Optional ordered keys of the index to drop, either name or keys is required"`
+	MaxTimeMillis  *int64            `json:"max_time_ms,omitempty" jsonschema:"Optional time limit of the operation in milliseconds, defaults to the server timeout of the operation and is capped by the server maximum"`
+}
+
+type MongoDBDropIndexToolOutput struct {
+	Name    string `json:"name" jsonschema:"The name of the dropped index"`
+	Dropped bool   `json:"dropped" jsonschema:"Whether the index was dropped"`
+}
+
+type NewMongoDBDropIndexTool struct {
+	tool *Tool
+}
+
+func (t *Tool) NewMongoDBDropIndexTool() *NewMongoDBDropIndexTool {
+	return &NewMongoDBDropIndexTool{
+		tool: t,
+	}
+}
+
+func (t *NewMongoDBDropIndexTool) name() string {
+	return "[MongoDB] Drop Index Tool"
+}
+
+func (t *NewMongoDBDropIndexTool) description() string {
+	return "# Drop an index in MongoDB.\n\n" +
+		"This tool can be used to drop an index of a MongoDB collection by its name or its keys.\n\n"
+}
+
+func (t *NewMongoDBDropIndexTool) toolCall(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	input MongoDBDropIndexToolInput,
+) (
+	*mcp.CallToolResult,
+	MongoDBDropIndexToolOutput,
+	error,
+) {
+	defResponse := MongoDBDropIndexToolOutput{
+		Dropped: false,
+	}
+
+	done, err := t.tool.quotas.acquire(req, operationAdmin)
+	if err != nil {
+		return nil, defResponse, err
+	}
+	defer done()
+
+	ctx, cancel := t.tool.timeouts.withTimeout(ctx, operationAdmin, input.MaxTimeMillis)
+	defer cancel()
+
+	if (input.Name == nil || *input.Name == "") && len(input.Keys) == 0 {
+		return nil, defResponse, fmt.Errorf("Either the name or the keys of the index to drop are required")
+	}
+
+	DB, err := t.tool.Database(input.DatabaseName)
+	if err != nil {
+		return nil, defResponse, err
+	}
+
+	collection := DB.Collection(input.CollectionName)
+
+	indexes, err := listIndexes(ctx, collection)
+	if err != nil {
+		return nil, defResponse, err
+	}
+
+	name := ""
+	for _, index := range indexes {
+		if input.Name != nil && *input.Name != "" {
+			if index.Name == *input.Name {
+				name = index.Name
+			}
+		} else if len(input.Keys) > 0 && sameIndexKeys(index.Keys, storedIndexKeys(input.Keys)) {
+			name = index.Name
+		}
+	}
+
+	if name == "" {
+		return nil, defResponse, fmt.Errorf("No index matches the given name or keys")
+	}
+	if name == "_id_" {
+		return nil, defResponse, fmt.Errorf("The _id index cannot be dropped")
+	}
+
+	if err := collection.Indexes().DropOne(ctx, name); err != nil {
+		return nil, defResponse, err
+	}
+
+	return nil, MongoDBDropIndexToolOutput{
+		Name:    name,
+		Dropped: true,
+	}, nil
+}
+
+func (t *NewMongoDBDropIndexTool) AttachTool(server *mcp.Server) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        t.name(),
+		Description: t.description(),
+	}, t.toolCall)
+}
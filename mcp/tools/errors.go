@@ -0,0 +1,329 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/x/mongo/driver/topology"
+)
+
+// The stable codes of the tool errors.
+const (
+	errorCodeDuplicateKey        = "DUPLICATE_KEY"
+	errorCodeValidationFailed    = "DOCUMENT_VALIDATION_FAILED"
+	errorCodeUnauthorized        = "UNAUTHORIZED"
+	errorCodeAuthenticationError = "AUTHENTICATION_FAILED"
+	errorCodeTimeout             = "TIMEOUT"
+	errorCodeCancelled           = "CANCELLED"
+	errorCodeNetwork             = "NETWORK_ERROR"
+	errorCodeNoDocuments         = "NO_DOCUMENTS"
+	errorCodeUnknownOperator     = "UNKNOWN_OPERATOR"
+	errorCodeBadValue            = "BAD_VALUE"
+	errorCodeNamespaceNotFound   = "NAMESPACE_NOT_FOUND"
+	errorCodeTransactionConflict = "TRANSACTION_CONFLICT"
+	errorCodeQueryRefused        = "QUERY_REFUSED"
+	errorCodePipelineRefused     = "PIPELINE_REFUSED"
+	errorCodeFilterRefused       = "FILTER_REFUSED"
+	errorCodeScopeRefused        = "SCOPE_REFUSED"
+	errorCodeRateLimited         = "RATE_LIMITED"
+	errorCodeServer              = "SERVER_ERROR"
+	errorCodeInvalidRequest      = "INVALID_REQUEST"
+)
+
+// The server error codes classified by toolError.
+const (
+	serverCodeBadValue                   = 2
+	serverCodeFailedToParse              = 9
+	serverCodeUnauthorized               = 13
+	serverCodeTypeMismatch               = 14
+	serverCodeAuthenticationFailed       = 18
+	serverCodeNamespaceNotFound          = 26
+	serverCodeConflictingUpdateOperators = 40
+	serverCodeImmutableField             = 66
+	serverCodeWriteConflict              = 112
+	serverCodeDocumentValidationFailure  = 121
+	serverCodeNoSuchTransaction          = 251
+	serverCodeUnrecognizedStage          = 40324
+)
+
+var errorHints = map[string]string{
+	errorCodeDuplicateKey:        "A document with the same value of a unique index already exists, use another value or update the existing document instead.",
+	errorCodeValidationFailed:    "The document does not satisfy the validator of the collection, read the collection schema and fix the fields reported in details.",
+	errorCodeUnauthorized:        "The database user lacks the privileges for this operation, use another operation or ask an administrator to grant them.",
+	errorCodeAuthenticationError: "The server rejected the credentials, check the username, password and authSource of DB_URL.",
+	errorCodeTimeout:             "The operation took too long, narrow the filter, add an index, or raise max_time_ms up to the server maximum.",
+	errorCodeCancelled:           "The request was cancelled before the operation completed.",
+	errorCodeNetwork:             "The server could not be reached, retry the operation later.",
+	errorCodeNoDocuments:         "No document matched the filter, check the filter values and their types, e.g. an ObjectId versus a string _id.",
+	errorCodeUnknownOperator:     "The operator or stage is not supported by the server, check its spelling and the MongoDB version.",
+	errorCodeBadValue:            "The filter, update or pipeline is malformed, check its syntax and the types of its values.",
+	errorCodeNamespaceNotFound:   "The database or collection does not exist, list the collections to find the right name.",
+	errorCodeTransactionConflict: "The transaction conflicted with another operation or is no longer open, abort it and retry the whole transaction.",
+	errorCodeQueryRefused:        "Narrow the filter or create the suggested index, see details.",
+	errorCodePipelineRefused:     "Remove or replace the refused stage or operator, see details for its path in the pipeline.",
+	errorCodeFilterRefused:       "Rewrite the filter without the refused operator or field, or split it into smaller queries, see details for its path.",
+	errorCodeScopeRefused:        "The operation would reach documents outside of the scope of the session, leave the scope fields unchanged, see details.",
+	errorCodeRateLimited:         "Too many calls, wait retry_after_ms before calling again and combine the work into fewer calls, the quota status tool reports the usage.",
+	errorCodeServer:              "The server rejected the operation, see the message.",
+	errorCodeInvalidRequest:      "Check the tool arguments against the message.",
+}
+
+var (
+	operatorPattern  = regexp.MustCompile(`\$[A-Za-z][A-Za-z0-9_]*`)
+	duplicatePattern = regexp.MustCompile(`dup key: \{ ?([^:]+): (.*?) ?\}`)
+)
+
+// MongoDBToolError is a classified tool error, reported to the model as a
+// message followed by its JSON representation.
+type MongoDBToolError struct {
+	Code       string `json:"code"`
+	Message    string `json:"message"`
+	ServerCode int    `json:"server_code,omitempty"`
+	Field      string `json:"field,omitempty"`
+	Value      any    `json:"value,omitempty"`
+	Details    any    `json:"details,omitempty"`
+	Retryable  bool   `json:"retryable"`
+	Retries    int    `json:"retries,omitempty"`
+	RetryAfter int64  `json:"retry_after_ms,omitempty"`
+	Hint       string `json:"hint"`
+
+	err error
+}
+
+func (e *MongoDBToolError) Error() string {
+	details, err := json.Marshal(e)
+	if err != nil {
+		return e.Code + ": " + e.Message
+	}
+	return e.Code + ": " + e.Message + "\n\n" + string(details)
+}
+
+func (e *MongoDBToolError) Unwrap() error {
+	return e.err
+}
+
+// serverError returns the code, message and raw document of the first error
+// reported by the server.
+func serverError(err error) (code int, message string, raw bson.Raw, ok bool) {
+	var writeErr mongo.WriteException
+	if errors.As(err, &writeErr) && len(writeErr.WriteErrors) > 0 {
+		first := writeErr.WriteErrors[0]
+		return first.Code, first.Message, first.Raw, true
+	}
+	if errors.As(err, &writeErr) && writeErr.WriteConcernError != nil {
+		return writeErr.WriteConcernError.Code, writeErr.WriteConcernError.Message, writeErr.WriteConcernError.Raw, true
+	}
+
+	var bulkErr mongo.BulkWriteException
+	if errors.As(err, &bulkErr) && len(bulkErr.WriteErrors) > 0 {
+		first := bulkErr.WriteErrors[0]
+		return first.Code, first.Message, first.Raw, true
+	}
+
+	var commandErr mongo.CommandError
+	if errors.As(err, &commandErr) && commandErr.Code != 0 {
+		return int(commandErr.Code), commandErr.Message, commandErr.Raw, true
+	}
+
+	return 0, "", nil, false
+}
+
+// duplicateKey returns the field and value of a duplicate key error, from the
+// keyValue of the error or from its message on older servers.
+func duplicateKey(message string, raw bson.Raw) (string, any) {
+	if document, ok := raw.Lookup("keyValue").DocumentOK(); ok {
+		elements, err := document.Elements()
+		if err == nil && len(elements) > 0 {
+			var value any
+			if err := elements[0].Value().Unmarshal(&value); err == nil {
+				return elements[0].Key(), value
+			}
+			return elements[0].Key(), nil
+		}
+	}
+
+	if match := duplicatePattern.FindStringSubmatch(message); match != nil {
+		return strings.TrimSpace(match[1]), strings.Trim(match[2], `"`)
+	}
+	return "", nil
+}
+
+// validationField returns the first property reported in the details of a
+// document validation failure.
+func validationField(details bson.Raw) string {
+	elements, err := details.Elements()
+	if err != nil {
+		return ""
+	}
+
+	for _, element := range elements {
+		value := element.Value()
+		switch {
+		case element.Key() == "propertyName":
+			if name, ok := value.StringValueOK(); ok {
+				return name
+			}
+		case element.Key() == "missingProperties":
+			if array, ok := value.ArrayOK(); ok {
+				if values, err := array.Values(); err == nil && len(values) > 0 {
+					if name, ok := values[0].StringValueOK(); ok {
+						return name
+					}
+				}
+			}
+		}
+		if document, ok := value.DocumentOK(); ok {
+			if field := validationField(document); field != "" {
+				return field
+			}
+		}
+		if array, ok := value.ArrayOK(); ok {
+			if field := validationField(bson.Raw(array)); field != "" {
+				return field
+			}
+		}
+	}
+
+	return ""
+}
+
+// toolError classifies the error of a tool call.
+func toolError(err error) *MongoDBToolError {
+	var classified *MongoDBToolError
+	if errors.As(err, &classified) {
+		return classified
+	}
+
+	result := &MongoDBToolError{
+		Code:    errorCodeServer,
+		Message: err.Error(),
+		err:     err,
+	}
+
+	var retried *retriedError
+	if errors.As(err, &retried) {
+		result.Retries = retried.retries
+	}
+
+	code, message, raw, isServerError := serverError(err)
+	if isServerError {
+		result.ServerCode = code
+		result.Message = message
+	}
+
+	var guardErr *queryGuardError
+	var pipelineErr *pipelinePolicyError
+	var filterErr *filterPolicyError
+	var scopeErr *scopeError
+	var rateErr *rateLimitError
+	var labeled mongo.LabeledError
+	switch {
+	case errors.As(err, &guardErr):
+		result.Code = errorCodeQueryRefused
+		result.Message = guardErr.message()
+		result.Details = guardErr
+	case errors.As(err, &pipelineErr):
+		result.Code = errorCodePipelineRefused
+		result.Message = pipelineErr.message()
+		result.Field = pipelineErr.Stage
+		result.Details = pipelineErr
+	case errors.As(err, &filterErr):
+		result.Code = errorCodeFilterRefused
+		result.Message = filterErr.message()
+		result.Field = filterErr.Path
+		result.Details = filterErr
+	case errors.As(err, &scopeErr):
+		result.Code = errorCodeScopeRefused
+		result.Message = scopeErr.message()
+		result.Field = scopeErr.Field
+		result.Details = scopeErr
+	case errors.As(err, &rateErr):
+		result.Code = errorCodeRateLimited
+		result.Message = rateErr.message()
+		result.Field = rateErr.Limit
+		result.Details = rateErr
+		result.Retryable = true
+		result.RetryAfter = rateErr.RetryAfterMillis
+	case errors.Is(err, mongo.ErrNoDocuments):
+		result.Code = errorCodeNoDocuments
+		result.Message = noDocumentMatched
+	case errors.Is(err, context.Canceled):
+		result.Code = errorCodeCancelled
+	case errors.As(err, &topology.ServerSelectionError{}):
+		result.Code = errorCodeNetwork
+		result.Retryable = true
+	case mongo.IsTimeout(err):
+		result.Code = errorCodeTimeout
+		result.Message = "The operation exceeded its time limit: " + result.Message
+		result.Retryable = true
+	case mongo.IsNetworkError(err):
+		result.Code = errorCodeNetwork
+		result.Retryable = true
+	case mongo.IsDuplicateKeyError(err):
+		result.Code = errorCodeDuplicateKey
+		result.Field, result.Value = duplicateKey(result.Message, raw)
+	case code == serverCodeDocumentValidationFailure:
+		result.Code = errorCodeValidationFailed
+		if details, ok := raw.Lookup("errInfo").DocumentOK(); ok {
+			result.Field = validationField(details)
+			var decoded bson.M
+			if err := bson.Unmarshal(details, &decoded); err == nil {
+				result.Details = decoded
+			}
+		}
+	case code == serverCodeUnauthorized:
+		result.Code = errorCodeUnauthorized
+	case code == serverCodeAuthenticationFailed || (!isServerError && strings.Contains(err.Error(), "auth error")):
+		result.Code = errorCodeAuthenticationError
+	case code == serverCodeNamespaceNotFound:
+		result.Code = errorCodeNamespaceNotFound
+	case code == serverCodeWriteConflict || code == serverCodeNoSuchTransaction ||
+		(errors.As(err, &labeled) && labeled.HasErrorLabel("TransientTransactionError")):
+		result.Code = errorCodeTransactionConflict
+		result.Retryable = true
+	case code == serverCodeUnrecognizedStage ||
+		((code == serverCodeBadValue || code == serverCodeFailedToParse) && strings.Contains(strings.ToLower(result.Message), "unknown")):
+		result.Code = errorCodeUnknownOperator
+		result.Field = operatorPattern.FindString(result.Message)
+	case code == serverCodeBadValue || code == serverCodeFailedToParse || code == serverCodeTypeMismatch ||
+		code == serverCodeConflictingUpdateOperators || code == serverCodeImmutableField:
+		result.Code = errorCodeBadValue
+		result.Field = operatorPattern.FindString(result.Message)
+	case retryableRead(err):
+		// Primary stepdowns and shutdowns keep the server error code.
+		result.Retryable = true
+	case !isServerError:
+		// Errors that do not come from the driver are raised by the tools
+		// while validating their input.
+		result.Code = errorCodeInvalidRequest
+	}
+	result.Hint = errorHints[result.Code]
+
+	return result
+}
+
+// TranslateToolErrors is a server middleware replacing the raw errors of the
+// tool calls with classified errors carrying a stable code, the offending
+// field and a remediation hint.
+func TranslateToolErrors(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		res, err := next(ctx, method, req)
+		if err != nil || method != "tools/call" {
+			return res, err
+		}
+
+		result, ok := res.(*mcp.CallToolResult)
+		if !ok || !result.IsError || result.GetError() == nil {
+			return res, err
+		}
+		result.SetError(toolError(result.GetError()))
+
+		return result, nil
+	}
+}
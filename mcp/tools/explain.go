@@ -0,0 +1,156 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type MongoDBExplainToolInput struct {
+	DatabaseName   *string          `json:"database_name,omitempty" jsonschema:"Optional name of the database of the collection"`
+	CollectionName string           `json:"collection_name" jsonschema:"Name of the collection to explain the query on"`
+	Operation      string           `json:"operation" jsonschema:"The operation to explain, one of find, count or aggregate"`
+	Filter         bson.M           `json:"filter,omitempty" jsonschema:"Optional filter of the find or count operation"`
+	Sort           []MongoDBSortKey `json:"sort,omitempty" jsonschema:"Optional ordered sort of the find operation"`
+	Projection     bson.M           `json:"projection,omitempty" jsonschema:"Optional projection of the find operation"`
+	Skip           *int64           `json:"skip,omitempty" jsonschema:"Optional number of documents to skip for the find or count operation"`
+	Limit          *int64           `json:"limit,omitempty" jsonschema:"Optional maximum number of documents for the find or count operation"`
+	Pipeline       []bson.M         `json:"pipeline,omitempty" jsonschema:"Optional aggregation pipeline of the aggregate operation"`
+	Verbosity      *string          `json:"verbosity,omitempty" jsonschema:"Optional verbosity, one of queryPlanner, executionStats or allPlansExecution, defaults to executionStats"`
+	MaxTimeMillis  *int64           `json:"max_time_ms,omitempty" jsonschema:"Optional time limit of the operation in milliseconds, defaults to the server timeout of the operation and is capped by the server maximum"`
+}
+
+type MongoDBExplainToolOutput struct {
+	Summary *MongoDBExplainSummary `json:"summary" jsonschema:"The condensed summary of the winning plan"`
+	Explain bson.M                 `json:"explain" jsonschema:"The raw output of the explain command"`
+}
+
+type NewMongoDBExplainTool struct {
+	tool *Tool
+}
+
+func (t *Tool) NewMongoDBExplainTool() *NewMongoDBExplainTool {
+	return &NewMongoDBExplainTool{
+		tool: t,
+	}
+}
+
+func (t *NewMongoDBExplainTool) name() string {
+	return "[MongoDB] Explain Tool"
+}
+
+func (t *NewMongoDBExplainTool) description() string {
+	return "# Explain a query in MongoDB.\n\n" +
+		"This tool can be used to explain a find, count or aggregate operation on a MongoDB collection.\n\n" +
+		"It returns a summary of the winning plan with the stages, the indexes used, whether the collection is scanned " +
+		"and the number of documents and keys examined versus returned, alongside the raw explain output. " +
+		"Use it to check that a query uses an index before running it on a large collection.\n\n"
+}
+
+func (t *NewMongoDBExplainTool) toolCall(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	input MongoDBExplainToolInput,
+) (
+	*mcp.CallToolResult,
+	MongoDBExplainToolOutput,
+	error,
+) {
+	defResponse := MongoDBExplainToolOutput{
+		Summary: nil,
+	}
+
+	done, err := t.tool.quotas.acquire(req, operationRead)
+	if err != nil {
+		return nil, defResponse, err
+	}
+	defer done()
+
+	ctx, cancel := t.tool.timeouts.withTimeout(ctx, operationRead, input.MaxTimeMillis)
+	defer cancel()
+
+	if input.Operation == "aggregate" && !t.tool.AllowAggregates {
+		return nil, defResponse, fmt.Errorf("Aggregate operations are not allowed on this server")
+	}
+	if input.Operation == "aggregate" {
+		if err := t.tool.pipelines.check(input.Pipeline); err != nil {
+			return nil, defResponse, err
+		}
+	}
+
+	if err := t.tool.filters.check(input.Filter, "filter"); err != nil {
+		return nil, defResponse, err
+	}
+
+	DB, err := t.tool.Database(input.DatabaseName)
+	if err != nil {
+		return nil, defResponse, err
+	}
+
+	collection := DB.Collection(input.CollectionName)
+
+	if err := t.tool.redaction.checkQuery(collectionNamespace(collection), input.Filter, input.Sort); err != nil {
+		return nil, defResponse, err
+	}
+	if err := t.tool.redaction.checkPipeline(collectionNamespace(collection), input.Pipeline); err != nil {
+		return nil, defResponse, err
+	}
+
+	scope, err := t.tool.scope.resolve(req.Session, collectionNamespace(collection))
+	if err != nil {
+		return nil, defResponse, err
+	}
+	filter := scopedFilter(scope, input.Filter)
+	pipeline, err := t.tool.scope.pipeline(req.Session, DB.Name(), collection.Name(), input.Pipeline)
+	if err != nil {
+		return nil, defResponse, err
+	}
+
+	sort, err := sortDocument(input.Sort)
+	if err != nil {
+		return nil, defResponse, err
+	}
+
+	query := explainQuery{
+		operation:  input.Operation,
+		filter:     filter,
+		sort:       sort,
+		projection: input.Projection,
+		pipeline:   pipeline,
+	}
+	if input.Skip != nil && *input.Skip > 0 {
+		query.skip = *input.Skip
+	}
+	if input.Limit != nil && *input.Limit > 0 {
+		query.limit = *input.Limit
+	}
+
+	verbosity := ""
+	if input.Verbosity != nil {
+		verbosity = *input.Verbosity
+	}
+
+	raw, summary, err := explain(ctx, collection, query, verbosity)
+	if err != nil {
+		return nil, defResponse, err
+	}
+
+	var result bson.M
+	if err := bson.Unmarshal(raw, &result); err != nil {
+		return nil, defResponse, err
+	}
+
+	return nil, MongoDBExplainToolOutput{
+		Summary: summary,
+		Explain: result,
+	}, nil
+}
+
+func (t *NewMongoDBExplainTool) AttachTool(server *mcp.Server) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        t.name(),
+		Description: t.description(),
+	}, t.toolCall)
+}
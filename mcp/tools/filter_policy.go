@@ -0,0 +1,118 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// defaultDeniedFilterOperators are the operators refused in filters when
+// FILTER_DENIED_OPERATORS is not set, they run JavaScript on the server.
+const defaultDeniedFilterOperators = "$where,$function,$accumulator"
+
+// filterPolicy validates the filters given to the tools before they reach
+// the driver, refusing the denied operators, deeply nested filters and large
+// $in lists.
+type filterPolicy struct {
+	deniedOperators map[string]bool
+	maxDepth        int
+	maxInSize       int
+}
+
+type filterPolicyError struct {
+	Reason   string `json:"reason"`
+	Operator string `json:"operator,omitempty"`
+	Path     string `json:"path"`
+}
+
+func (e *filterPolicyError) message() string {
+	return "Filter refused: " + e.Reason + "."
+}
+
+func (e *filterPolicyError) Error() string {
+	message := e.message()
+
+	details, err := json.Marshal(e)
+	if err != nil {
+		return message
+	}
+	return message + "\n\n" + string(details)
+}
+
+func newFilterPolicy() *filterPolicy {
+	return &filterPolicy{
+		deniedOperators: parseNameList(defaultDeniedFilterOperators),
+		maxDepth:        20,
+		maxInSize:       1000,
+	}
+}
+
+// check returns a *filterPolicyError when the filter is not allowed. The
+// path names the filter in the tool input, e.g. "filter".
+func (p *filterPolicy) check(filter map[string]any, path string) error {
+	if p == nil || filter == nil {
+		return nil
+	}
+	return p.checkValue(filter, path, 1)
+}
+
+// checkPipeline checks every stage of a pipeline as a filter, for the
+// pipelines of the change streams whose stages are not otherwise checked.
+func (p *filterPolicy) checkPipeline(pipeline []bson.M, path string) error {
+	if p == nil {
+		return nil
+	}
+	for i, stage := range pipeline {
+		if err := p.checkValue(stage, fmt.Sprintf("%s.%d", path, i), 1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *filterPolicy) checkValue(value any, path string, depth int) error {
+	document, isDocument := pipelineDocument(value)
+	values, isArray := pipelineStages(value)
+	if !isDocument && !isArray {
+		return nil
+	}
+
+	if p.maxDepth > 0 && depth > p.maxDepth {
+		return &filterPolicyError{
+			Reason: fmt.Sprintf("the filter is nested deeper than %d levels", p.maxDepth),
+			Path:   path,
+		}
+	}
+
+	for i, nested := range values {
+		if err := p.checkValue(nested, fmt.Sprintf("%s.%d", path, i), depth+1); err != nil {
+			return err
+		}
+	}
+
+	for key, nested := range document {
+		keyPath := path + "." + key
+		if p.deniedOperators[key] {
+			return &filterPolicyError{
+				Reason:   fmt.Sprintf("the %s operator is not allowed", key),
+				Operator: key,
+				Path:     keyPath,
+			}
+		}
+		if key == "$in" || key == "$nin" {
+			if list, ok := pipelineStages(nested); ok && p.maxInSize > 0 && len(list) > p.maxInSize {
+				return &filterPolicyError{
+					Reason:   fmt.Sprintf("the %s list has %d values, more than the maximum of %d", key, len(list), p.maxInSize),
+					Operator: key,
+					Path:     keyPath,
+				}
+			}
+		}
+		if err := p.checkValue(nested, keyPath, depth+1); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -9,11 +9,14 @@ import (
 )
 
 type MongoDBFindToolInput struct {
-	DatabaseName   *string `json:"database_name,omitempty" jsonschema:"Optional name of the database to find the document in"`
-	CollectionName string  `json:"collection_name" jsonschema:"Name of the collection to find the document in"`
-	Filter         bson.M  `json:"filter" jsonschema:"The filter to find the document with"`
-	Skip           *int64  `json:"skip,omitempty" jsonschema:"Optional number of documents to skip"`
-	Limit          *int64  `json:"limit,omitempty" jsonschema:"Optional maximum number of documents to return, defaults to 10"`
+	DatabaseName        *string `json:"database_name,omitempty" jsonschema:"Optional name of the database to find the document in"`
+	CollectionName      string  `json:"collection_name" jsonschema:"Name of the collection to find the document in"`
+	Filter              bson.M  `json:"filter" jsonschema:"The filter to find the document with"`
+	Skip                *int64  `json:"skip,omitempty" jsonschema:"Optional number of documents to skip"`
+	Limit               *int64  `json:"limit,omitempty" jsonschema:"Optional maximum number of documents to return, defaults to 10"`
+	AllowCollectionScan *bool   `json:"allow_collection_scan,omitempty" jsonschema:"Optional confirmation to run the operation even if the query cost guard detects a collection scan, only honoured when the server asks for confirmation"`
+	TransactionID       *string `json:"transaction_id,omitempty" jsonschema:"Optional id of a transaction returned by the Begin Transaction tool to run the operation in"`
+	MaxTimeMillis       *int64  `json:"max_time_ms,omitempty" jsonschema:"Optional time limit of the operation in milliseconds, defaults to the server timeout of the operation and is capped by the server maximum"`
 }
 
 type MongoDBFindToolOutput struct {
@@ -56,6 +59,19 @@ func (t *NewMongoDBFindTool) toolCall(
 		Total:     0,
 	}
 
+	done, err := t.tool.quotas.acquire(req, operationRead)
+	if err != nil {
+		return nil, defResponse, err
+	}
+	defer done()
+
+	ctx, cancel := t.tool.timeouts.withTimeout(ctx, operationRead, input.MaxTimeMillis)
+	defer cancel()
+
+	if err := t.tool.filters.check(input.Filter, "filter"); err != nil {
+		return nil, defResponse, err
+	}
+
 	DB, err := t.tool.Database(input.DatabaseName)
 	if err != nil {
 		return nil, defResponse, err
@@ -63,6 +79,16 @@ func (t *NewMongoDBFindTool) toolCall(
 
 	collection := DB.Collection(input.CollectionName)
 
+	if err := t.tool.redaction.checkQuery(collectionNamespace(collection), input.Filter, nil); err != nil {
+		return nil, defResponse, err
+	}
+
+	scope, err := t.tool.scope.resolve(req.Session, collectionNamespace(collection))
+	if err != nil {
+		return nil, defResponse, err
+	}
+	filter := scopedFilter(scope, input.Filter)
+
 	var limit int64 = 10
 	if input.Limit != nil && *input.Limit > 0 {
 		limit = *input.Limit
@@ -72,36 +98,58 @@ func (t *NewMongoDBFindTool) toolCall(
 		skip = *input.Skip
 	}
 
-	filterOptions := options.Find().SetLimit(limit).SetSkip(skip)
-
-	total, err := collection.CountDocuments(ctx, input.Filter)
-	if err != nil {
+	query := explainQuery{operation: "find", filter: filter, limit: limit, skip: skip}
+	if err := t.tool.guard.check(ctx, collection, query, input.AllowCollectionScan); err != nil {
 		return nil, defResponse, err
 	}
 
-	var results []bson.M = []bson.M{}
-	cursor, err := collection.Find(ctx, input.Filter, filterOptions)
+	ctx, release, err := t.tool.transactions.join(ctx, req.Session, input.TransactionID)
 	if err != nil {
 		return nil, defResponse, err
 	}
-	defer cursor.Close(ctx)
+	defer release()
+
+	filterOptions := options.Find().SetLimit(limit).SetSkip(skip)
 
-	for cursor.Next(ctx) {
-		var result bson.M
-		err = cursor.Decode(&result)
+	progress := newProgressReporter(req)
+	var total int64
+	var results []bson.M
+	retries, err := t.tool.retry.do(ctx, func(ctx context.Context) error {
+		var err error
+		total, err = collection.CountDocuments(ctx, filter)
 		if err != nil {
-			return nil, defResponse, err
+			return err
 		}
-		results = append(results, result)
+
+		results = []bson.M{}
+		cursor, err := collection.Find(ctx, filter, filterOptions)
+		if err != nil {
+			return err
+		}
+		defer cursor.Close(ctx)
+
+		expected := min(limit, max(total-skip, 0))
+		for cursor.Next(ctx) {
+			var result bson.M
+			if err := cursor.Decode(&result); err != nil {
+				return err
+			}
+			results = append(results, result)
+			progress.report(ctx, int64(len(results)), expected, "Fetching documents")
+		}
+		return cursor.Err()
+	})
+	if err != nil {
+		return nil, defResponse, err
 	}
 
 	output := MongoDBFindToolOutput{
-		Documents: results,
+		Documents: t.tool.redaction.redactAll(collectionNamespace(collection), results),
 		HasMore:   int64(len(results))+skip < total,
 		Total:     total,
 	}
 
-	return nil, output, nil
+	return retryResult(retries), output, nil
 }
 
 func (t *NewMongoDBFindTool) AttachTool(server *mcp.Server) {
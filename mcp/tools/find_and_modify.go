@@ -0,0 +1,32 @@
+package tools
+
+import (
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// noDocumentMatched is the message of the find-and-modify tools when the
+// filter matches no document.
+const noDocumentMatched = "No document matched the filter"
+
+// noDocumentUpserted is the message of the find-and-modify tools when the
+// filter matches no document and a new one is upserted, which is only
+// returned with return_document set to after.
+const noDocumentUpserted = "No document matched the filter, a new document was upserted and is returned only with return_document set to after"
+
+// returnDocument parses the return_document input of the find-and-modify
+// tools, which defaults to the document after the modification.
+func returnDocument(value *string) (options.ReturnDocument, error) {
+	if value == nil || *value == "" {
+		return options.After, nil
+	}
+
+	switch *value {
+	case "before":
+		return options.Before, nil
+	case "after":
+		return options.After, nil
+	}
+	return options.After, fmt.Errorf("Invalid return_document %q, use before or after", *value)
+}
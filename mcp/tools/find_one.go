@@ -11,6 +11,8 @@ type MongoDBFindOneToolInput struct {
 	DatabaseName   *string `json:"database_name,omitempty" jsonschema:"Optional name of the database to find the document in"`
 	CollectionName string  `json:"collection_name" jsonschema:"Name of the collection to find the document in"`
 	Filter         bson.M  `json:"filter" jsonschema:"The filter to find the document with"`
+	TransactionID  *string `json:"transaction_id,omitempty" jsonschema:"Optional id of a transaction returned by the Begin Transaction tool to run the operation in"`
+	MaxTimeMillis  *int64  `json:"max_time_ms,omitempty" jsonschema:"Optional time limit of the operation in milliseconds, defaults to the server timeout of the operation and is capped by the server maximum"`
 }
 
 type MongoDBFindOneToolOutput struct {
@@ -49,6 +51,19 @@ func (t *NewMongoDBFindOneTool) toolCall(
 		Document: bson.M{},
 	}
 
+	done, err := t.tool.quotas.acquire(req, operationRead)
+	if err != nil {
+		return nil, defResponse, err
+	}
+	defer done()
+
+	ctx, cancel := t.tool.timeouts.withTimeout(ctx, operationRead, input.MaxTimeMillis)
+	defer cancel()
+
+	if err := t.tool.filters.check(input.Filter, "filter"); err != nil {
+		return nil, defResponse, err
+	}
+
 	DB, err := t.tool.Database(input.DatabaseName)
 	if err != nil {
 		return nil, defResponse, err
@@ -56,17 +71,35 @@ func (t *NewMongoDBFindOneTool) toolCall(
 
 	collection := DB.Collection(input.CollectionName)
 
+	if err := t.tool.redaction.checkQuery(collectionNamespace(collection), input.Filter, nil); err != nil {
+		return nil, defResponse, err
+	}
+
+	scope, err := t.tool.scope.resolve(req.Session, collectionNamespace(collection))
+	if err != nil {
+		return nil, defResponse, err
+	}
+	filter := scopedFilter(scope, input.Filter)
+
+	ctx, release, err := t.tool.transactions.join(ctx, req.Session, input.TransactionID)
+	if err != nil {
+		return nil, defResponse, err
+	}
+	defer release()
+
 	var result bson.M
-	err = collection.FindOne(ctx, input.Filter).Decode(&result)
+	retries, err := t.tool.retry.do(ctx, func(ctx context.Context) error {
+		return collection.FindOne(ctx, filter).Decode(&result)
+	})
 	if err != nil {
 		return nil, defResponse, err
 	}
 
 	output := MongoDBFindOneToolOutput{
-		Document: result,
+		Document: t.tool.redaction.redact(collectionNamespace(collection), result),
 	}
 
-	return nil, output, nil
+	return retryResult(retries), output, nil
 }
 
 func (t *NewMongoDBFindOneTool) AttachTool(server *mcp.Server) {
@@ -2,20 +2,29 @@ package tools
 
 import (
 	"context"
+	"errors"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
 	"go.mongodb.org/mongo-driver/v2/mongo/options"
 )
 
 type MongoDBFindOneAndDeleteToolInput struct {
-	DatabaseName   *string `json:"database_name,omitempty" jsonschema:"Optional name of the database to find the document in"`
-	CollectionName string  `json:"collection_name" jsonschema:"Name of the collection to find the document in"`
-	Filter         bson.M  `json:"filter" jsonschema:"The filter to find the document with"`
+	DatabaseName        *string          `json:"database_name,omitempty" jsonschema:"Optional name of the database to find the document in"`
+	CollectionName      string           `json:"collection_name" jsonschema:"Name of the collection to find the document in"`
+	Filter              bson.M           `json:"filter" jsonschema:"The filter to find the document with"`
+	Sort                []MongoDBSortKey `json:"sort,omitempty" jsonschema:"Optional ordered sort deciding which document is modified when the filter matches several, e.g. the oldest first"`
+	Projection          bson.M           `json:"projection,omitempty" jsonschema:"Optional projection of the returned document"`
+	MaxTimeMillis       *int64           `json:"max_time_ms,omitempty" jsonschema:"Optional time limit of the operation in milliseconds, defaults to the server timeout of the operation and is capped by the server maximum"`
+	AllowCollectionScan *bool            `json:"allow_collection_scan,omitempty" jsonschema:"Optional confirmation to run the operation even if the query cost guard detects a collection scan, only honoured when the server asks for confirmation"`
+	TransactionID       *string          `json:"transaction_id,omitempty" jsonschema:"Optional id of a transaction returned by the Begin Transaction tool to run the operation in"`
 }
 
 type MongoDBFindOneAndDeleteToolOutput struct {
-	Document bson.M `json:"document" jsonschema:"The document that was deleted in the collection"`
+	Document bson.M `json:"document,omitempty" jsonschema:"The document that was deleted, as it was before the deletion, absent when no document matched"`
+	Matched  bool   `json:"matched" jsonschema:"Whether a document matched the filter"`
+	Message  string `json:"message,omitempty" jsonschema:"Explains the result when no document matched"`
 }
 
 type NewMongoDBFindOneAndDeleteTool struct {
@@ -50,6 +59,24 @@ func (t *NewMongoDBFindOneAndDeleteTool) toolCall(
 		Document: bson.M{},
 	}
 
+	done, err := t.tool.quotas.acquire(req, operationWrite)
+	if err != nil {
+		return nil, defResponse, err
+	}
+	defer done()
+
+	ctx, cancel := t.tool.timeouts.withTimeout(ctx, operationWrite, input.MaxTimeMillis)
+	defer cancel()
+
+	sort, err := sortDocument(input.Sort)
+	if err != nil {
+		return nil, defResponse, err
+	}
+
+	if err := t.tool.filters.check(input.Filter, "filter"); err != nil {
+		return nil, defResponse, err
+	}
+
 	DB, err := t.tool.Database(input.DatabaseName)
 	if err != nil {
 		return nil, defResponse, err
@@ -57,16 +84,54 @@ func (t *NewMongoDBFindOneAndDeleteTool) toolCall(
 
 	collection := DB.Collection(input.CollectionName)
 
+	if err := t.tool.redaction.checkQuery(collectionNamespace(collection), input.Filter, input.Sort); err != nil {
+		return nil, defResponse, err
+	}
+	if err := t.tool.redaction.checkProjection(collectionNamespace(collection), input.Projection); err != nil {
+		return nil, defResponse, err
+	}
+
+	scope, err := t.tool.scope.resolve(req.Session, collectionNamespace(collection))
+	if err != nil {
+		return nil, defResponse, err
+	}
+	filter := scopedFilter(scope, input.Filter)
+
+	query := explainQuery{operation: "find", filter: filter, sort: sort, limit: 1}
+	if err := t.tool.guard.check(ctx, collection, query, input.AllowCollectionScan); err != nil {
+		return nil, defResponse, err
+	}
+
+	ctx, release, err := t.tool.transactions.join(ctx, req.Session, input.TransactionID)
+	if err != nil {
+		return nil, defResponse, err
+	}
+	defer release()
+
 	opts := options.FindOneAndDelete()
+	if len(sort) > 0 {
+		opts.SetSort(sort)
+	}
+	if len(input.Projection) > 0 {
+		opts.SetProjection(input.Projection)
+	}
+
 	var result bson.M
-	err = collection.FindOneAndDelete(ctx, input.Filter, opts).
+	err = collection.FindOneAndDelete(ctx, filter, opts).
 		Decode(&result)
 	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, MongoDBFindOneAndDeleteToolOutput{
+				Matched: false,
+				Message: noDocumentMatched,
+			}, nil
+		}
 		return nil, defResponse, err
 	}
 
 	return nil, MongoDBFindOneAndDeleteToolOutput{
-		Document: result,
+		Document: t.tool.redaction.redact(collectionNamespace(collection), result),
+		Matched:  true,
 	}, nil
 }
 
@@ -2,22 +2,36 @@ package tools
 
 import (
 	"context"
+	"errors"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
 	"go.mongodb.org/mongo-driver/v2/mongo/options"
 )
 
 type MongoDBFindOneAndUpdateToolInput struct {
-	DatabaseName   *string `json:"database_name,omitempty" jsonschema:"Optional name of the database to find the document in"`
-	CollectionName string  `json:"collection_name" jsonschema:"Name of the collection to find the document in"`
-	Filter         bson.M  `json:"filter" jsonschema:"The filter to find the document with"`
-	Update         bson.M  `json:"update" jsonschema:"The update to apply to the document"`
-	Upsert         *bool   `json:"upsert,omitempty" jsonschema:"Optional whether to insert the document if it doesn't exist, defaults to false"`
+	DatabaseName        *string                `json:"database_name,omitempty" jsonschema:"Optional name of the database to find the document in"`
+	CollectionName      string                 `json:"collection_name" jsonschema:"Name of the collection to find the document in"`
+	Filter              bson.M                 `json:"filter" jsonschema:"The filter to find the document with"`
+	Update              any                    `json:"update" jsonschema:"The update to apply, either a document of update operators or an aggregation pipeline given as an array of stages"`
+	Upsert              *bool                  `json:"upsert,omitempty" jsonschema:"Optional whether to insert the document if it doesn't exist, defaults to false"`
+	ArrayFilters        []bson.M               `json:"array_filters,omitempty" jsonschema:"Optional filters selecting the array elements updated through the $[<identifier>] positional operator"`
+	Hint                *string                `json:"hint,omitempty" jsonschema:"Optional name of the index to use"`
+	Collation           *MongoDBCollationInput `json:"collation,omitempty" jsonschema:"Optional collation used to match the filter"`
+	Let                 bson.M                 `json:"let,omitempty" jsonschema:"Optional variables accessible as $$<name> in the filter and the update"`
+	ReturnDocument      *string                `json:"return_document,omitempty" jsonschema:"Optional document to return, before or after the modification, defaults to after"`
+	Sort                []MongoDBSortKey       `json:"sort,omitempty" jsonschema:"Optional ordered sort deciding which document is modified when the filter matches several, e.g. the oldest first"`
+	Projection          bson.M                 `json:"projection,omitempty" jsonschema:"Optional projection of the returned document"`
+	MaxTimeMillis       *int64                 `json:"max_time_ms,omitempty" jsonschema:"Optional time limit of the operation in milliseconds, defaults to the server timeout of the operation and is capped by the server maximum"`
+	AllowCollectionScan *bool                  `json:"allow_collection_scan,omitempty" jsonschema:"Optional confirmation to run the operation even if the query cost guard detects a collection scan, only honoured when the server asks for confirmation"`
+	TransactionID       *string                `json:"transaction_id,omitempty" jsonschema:"Optional id of a transaction returned by the Begin Transaction tool to run the operation in"`
 }
 
 type MongoDBFindOneAndUpdateToolOutput struct {
-	Document bson.M `json:"document" jsonschema:"The document that was updated in the collection"`
+	Document bson.M `json:"document,omitempty" jsonschema:"The document that was updated, as it was before or after the modification depending on return_document, absent when no document matched"`
+	Matched  bool   `json:"matched" jsonschema:"Whether a document matched the filter, or was upserted when the document after the modification is returned"`
+	Message  string `json:"message,omitempty" jsonschema:"Explains the result when no document matched"`
 }
 
 type NewMongoDBFindOneAndUpdateTool struct {
@@ -52,6 +66,36 @@ func (t *NewMongoDBFindOneAndUpdateTool) toolCall(
 		Document: bson.M{},
 	}
 
+	done, err := t.tool.quotas.acquire(req, operationWrite)
+	if err != nil {
+		return nil, defResponse, err
+	}
+	defer done()
+
+	ctx, cancel := t.tool.timeouts.withTimeout(ctx, operationWrite, input.MaxTimeMillis)
+	defer cancel()
+
+	update, err := updateDocument(input.Update)
+	if err != nil {
+		return nil, defResponse, err
+	}
+	if err := t.tool.pipelines.checkUpdate(update, "update"); err != nil {
+		return nil, defResponse, err
+	}
+
+	sort, err := sortDocument(input.Sort)
+	if err != nil {
+		return nil, defResponse, err
+	}
+	returnDoc, err := returnDocument(input.ReturnDocument)
+	if err != nil {
+		return nil, defResponse, err
+	}
+
+	if err := t.tool.filters.check(input.Filter, "filter"); err != nil {
+		return nil, defResponse, err
+	}
+
 	DB, err := t.tool.Database(input.DatabaseName)
 	if err != nil {
 		return nil, defResponse, err
@@ -59,20 +103,81 @@ func (t *NewMongoDBFindOneAndUpdateTool) toolCall(
 
 	collection := DB.Collection(input.CollectionName)
 
-	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	if err := t.tool.redaction.checkQuery(collectionNamespace(collection), input.Filter, input.Sort); err != nil {
+		return nil, defResponse, err
+	}
+	if err := t.tool.redaction.checkProjection(collectionNamespace(collection), input.Projection); err != nil {
+		return nil, defResponse, err
+	}
+
+	scope, err := t.tool.scope.resolve(req.Session, collectionNamespace(collection))
+	if err != nil {
+		return nil, defResponse, err
+	}
+	filter := scopedFilter(scope, input.Filter)
+	if err := scopeUpdate(scope, update, "update"); err != nil {
+		return nil, defResponse, err
+	}
+	if input.Upsert != nil && *input.Upsert {
+		if err := scopeUpsert(scope, input.Filter, "filter"); err != nil {
+			return nil, defResponse, err
+		}
+	}
+
+	query := explainQuery{operation: "find", filter: filter, sort: sort, limit: 1}
+	if err := t.tool.guard.check(ctx, collection, query, input.AllowCollectionScan); err != nil {
+		return nil, defResponse, err
+	}
+
+	ctx, release, err := t.tool.transactions.join(ctx, req.Session, input.TransactionID)
+	if err != nil {
+		return nil, defResponse, err
+	}
+	defer release()
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(returnDoc)
+	if len(sort) > 0 {
+		opts.SetSort(sort)
+	}
+	if len(input.Projection) > 0 {
+		opts.SetProjection(input.Projection)
+	}
 	if input.Upsert != nil && *input.Upsert {
 		opts.SetUpsert(*input.Upsert)
 	}
+	if len(input.ArrayFilters) > 0 {
+		opts.SetArrayFilters(arrayFilters(input.ArrayFilters))
+	}
+	if input.Hint != nil && *input.Hint != "" {
+		opts.SetHint(*input.Hint)
+	}
+	if input.Collation != nil {
+		opts.SetCollation(input.Collation.options())
+	}
+	if len(input.Let) > 0 {
+		opts.SetLet(input.Let)
+	}
 
 	var result bson.M
-	err = collection.FindOneAndUpdate(ctx, input.Filter, input.Update, opts).
+	err = collection.FindOneAndUpdate(ctx, filter, update, opts).
 		Decode(&result)
 	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			message := noDocumentMatched
+			if input.Upsert != nil && *input.Upsert {
+				message = noDocumentUpserted
+			}
+			return nil, MongoDBFindOneAndUpdateToolOutput{
+				Matched: false,
+				Message: message,
+			}, nil
+		}
 		return nil, defResponse, err
 	}
 
 	return nil, MongoDBFindOneAndUpdateToolOutput{
-		Document: result,
+		Document: t.tool.redaction.redact(collectionNamespace(collection), result),
+		Matched:  true,
 	}, nil
 }
 
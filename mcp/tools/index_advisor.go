@@ -0,0 +1,173 @@
+package tools
+
+import (
+	"sort"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// rangeOperators are the query operators that select a range of values and
+// therefore belong after the equality and sort fields of an index.
+var rangeOperators = map[string]bool{
+	"$gt":     true,
+	"$gte":    true,
+	"$lt":     true,
+	"$lte":    true,
+	"$ne":     true,
+	"$nin":    true,
+	"$regex":  true,
+	"$exists": true,
+	"$type":   true,
+	"$mod":    true,
+}
+
+// filterFields splits the fields of the filter into equality and range
+// fields, sorted by name within each document as the order of the fields of
+// a bson.M is random. Fields under $or and $nor are ignored as
+// each branch needs its own index.
+func filterFields(filter bson.M) (equality []string, ranges []string) {
+	seen := map[string]bool{}
+	var walk func(filter bson.M)
+	walk = func(filter bson.M) {
+		keys := make([]string, 0, len(filter))
+		for key := range filter {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			value := filter[key]
+			if key == "$and" {
+				for _, clause := range documentList(value) {
+					walk(clause)
+				}
+				continue
+			}
+			if strings.HasPrefix(key, "$") || seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			if isRangeCondition(value) {
+				ranges = append(ranges, key)
+			} else {
+				equality = append(equality, key)
+			}
+		}
+	}
+	walk(filter)
+
+	return equality, ranges
+}
+
+// isRangeCondition reports whether the condition of a field uses a range
+// operator, conditions without operators are equality matches.
+func isRangeCondition(condition any) bool {
+	document, ok := indexDocumentValue(condition)
+	if !ok {
+		return false
+	}
+	for operator := range document {
+		if rangeOperators[operator] {
+			return true
+		}
+	}
+	return false
+}
+
+func documentList(value any) []bson.M {
+	documents := []bson.M{}
+	array, ok := indexArrayValue(value)
+	if !ok {
+		return documents
+	}
+	for _, item := range array {
+		if document, ok := indexDocumentValue(item); ok {
+			documents = append(documents, document)
+		}
+	}
+	return documents
+}
+
+// suggestIndex proposes an index for the filter and sort following the
+// equality, sort, range rule.
+func suggestIndex(filter bson.M, sortKeys bson.D) []MongoDBIndexKey {
+	equality, ranges := filterFields(filter)
+
+	keys := []MongoDBIndexKey{}
+	used := map[string]bool{}
+	add := func(field string, direction int32) {
+		if used[field] {
+			return
+		}
+		used[field] = true
+		keys = append(keys, MongoDBIndexKey{Field: field, Value: direction})
+	}
+
+	for _, field := range equality {
+		add(field, 1)
+	}
+	for _, element := range sortKeys {
+		direction := int32(1)
+		if number, ok := indexNumber(element.Value); ok && number < 0 {
+			direction = -1
+		}
+		add(element.Key, direction)
+	}
+	for _, field := range ranges {
+		add(field, 1)
+	}
+
+	return keys
+}
+
+type MongoDBFieldSelectivity struct {
+	Field       string  `json:"field" jsonschema:"The filtered field"`
+	Selectivity float64 `json:"selectivity" jsonschema:"The ratio of sampled documents matching the condition on the field, lower is more selective"`
+}
+
+// isIndexPrefix reports whether the keys of prefix are the leading keys of
+// index.
+func isIndexPrefix(prefix, index []MongoDBIndexKey) bool {
+	return len(prefix) <= len(index) && sameIndexKeys(prefix, index[:len(prefix)])
+}
+
+// isCoveredProjection reports whether the projection only returns fields
+// stored in the index keys, so that the query does not need to fetch the
+// documents.
+func isCoveredProjection(projection bson.M, keys []MongoDBIndexKey) bool {
+	if len(projection) == 0 {
+		return false
+	}
+
+	indexed := map[string]bool{}
+	for _, key := range keys {
+		indexed[key.Field] = true
+	}
+
+	for field, value := range projection {
+		included := true
+		if number, ok := indexNumber(value); ok {
+			included = number != 0
+		} else if boolean, ok := value.(bool); ok {
+			included = boolean
+		}
+		if field == "_id" {
+			if included && !indexed["_id"] {
+				return false
+			}
+			continue
+		}
+		if !included || !indexed[field] {
+			return false
+		}
+	}
+
+	// _id is returned unless it is excluded explicitly.
+	if _, ok := projection["_id"]; !ok && !indexed["_id"] {
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,212 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+type MongoDBIndexUsageToolInput struct {
+	DatabaseName   *string `json:"database_name,omitempty" jsonschema:"Optional name of the database to inspect"`
+	CollectionName *string `json:"collection_name,omitempty" jsonschema:"Optional name of the collection to inspect, defaults to all collections of the database"`
+	Since          *string `json:"since,omitempty" jsonschema:"Optional RFC 3339 time, indexes whose access counters started after it are flagged as having insufficient history"`
+	MaxTimeMillis  *int64  `json:"max_time_ms,omitempty" jsonschema:"Optional time limit of the operation in milliseconds, defaults to the server timeout of the operation and is capped by the server maximum"`
+}
+
+type MongoDBIndexUsage struct {
+	Collection          string            `json:"collection" jsonschema:"The name of the collection"`
+	Name                string            `json:"name" jsonschema:"The name of the index"`
+	Keys                []MongoDBIndexKey `json:"keys" jsonschema:"The ordered keys of the index"`
+	Unique              bool              `json:"unique" jsonschema:"Whether the index is unique, unique indexes enforce a constraint even when unused"`
+	Accesses            int64             `json:"accesses" jsonschema:"The number of operations that used the index since the counters started"`
+	Since               string            `json:"since,omitempty" jsonschema:"When the access counters started, at the last server restart or index creation"`
+	NeverUsed           bool              `json:"never_used" jsonschema:"Whether the index has not been used since the counters started"`
+	InsufficientHistory bool              `json:"insufficient_history" jsonschema:"Whether the counters started after the requested since time"`
+	PrefixOf            []string          `json:"prefix_of,omitempty" jsonschema:"The indexes of the collection this index is a prefix of, which can serve the same queries"`
+	Size                *MongoDBSize      `json:"size,omitempty" jsonschema:"The storage size of the index"`
+}
+
+type MongoDBIndexUsageToolOutput struct {
+	Indexes          []MongoDBIndexUsage `json:"indexes" jsonschema:"The usage statistics of each index"`
+	UnusedIndexes    []string            `json:"unused_indexes" jsonschema:"The never used indexes as collection.index, excluding _id and unique indexes"`
+	RedundantIndexes []string            `json:"redundant_indexes" jsonschema:"The indexes that are prefixes of another index as collection.index, excluding unique indexes"`
+	ReclaimableSize  MongoDBSize         `json:"reclaimable_size" jsonschema:"The storage size of the unused and redundant indexes"`
+}
+
+type NewMongoDBIndexUsageTool struct {
+	tool *Tool
+}
+
+func (t *Tool) NewMongoDBIndexUsageTool() *NewMongoDBIndexUsageTool {
+	return &NewMongoDBIndexUsageTool{
+		tool: t,
+	}
+}
+
+func (t *NewMongoDBIndexUsageTool) name() string {
+	return "[MongoDB] Index Usage Tool"
+}
+
+func (t *NewMongoDBIndexUsageTool) description() string {
+	return "# Get the index usage statistics in MongoDB.\n\n" +
+		"This tool runs $indexStats on one or all collections of a MongoDB database and reports how often each index " +
+		"was used, the indexes that were never used, the indexes that are prefixes of other indexes and their storage cost.\n\n" +
+		"Use it to find write-amplifying indexes that can be dropped.\n\n"
+}
+
+// indexAccesses returns the accesses and the start of the counters of each
+// index, summed over all hosts.
+func (t *NewMongoDBIndexUsageTool) indexAccesses(ctx context.Context, collection *mongo.Collection) (map[string]int64, map[string]time.Time, error) {
+	cursor, err := collection.Aggregate(ctx, []bson.M{{"$indexStats": bson.M{}}})
+	if err != nil {
+		return nil, nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var stats []struct {
+		Name     string `bson:"name"`
+		Accesses struct {
+			Ops   int64     `bson:"ops"`
+			Since time.Time `bson:"since"`
+		} `bson:"accesses"`
+	}
+	if err := cursor.All(ctx, &stats); err != nil {
+		return nil, nil, err
+	}
+
+	accesses := map[string]int64{}
+	since := map[string]time.Time{}
+	for _, stat := range stats {
+		accesses[stat.Name] += stat.Accesses.Ops
+		if current, ok := since[stat.Name]; !ok || stat.Accesses.Since.After(current) {
+			since[stat.Name] = stat.Accesses.Since
+		}
+	}
+
+	return accesses, since, nil
+}
+
+func (t *NewMongoDBIndexUsageTool) toolCall(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	input MongoDBIndexUsageToolInput,
+) (
+	*mcp.CallToolResult,
+	MongoDBIndexUsageToolOutput,
+	error,
+) {
+	defResponse := MongoDBIndexUsageToolOutput{
+		Indexes:          []MongoDBIndexUsage{},
+		UnusedIndexes:    []string{},
+		RedundantIndexes: []string{},
+	}
+
+	done, err := t.tool.quotas.acquire(req, operationRead)
+	if err != nil {
+		return nil, defResponse, err
+	}
+	defer done()
+
+	ctx, cancel := t.tool.timeouts.withTimeout(ctx, operationRead, input.MaxTimeMillis)
+	defer cancel()
+
+	var since *time.Time
+	if input.Since != nil && *input.Since != "" {
+		parsed, err := time.Parse(time.RFC3339, *input.Since)
+		if err != nil {
+			return nil, defResponse, fmt.Errorf("Invalid since time, use the RFC 3339 format: %s", err.Error())
+		}
+		since = &parsed
+	}
+
+	DB, err := t.tool.Database(input.DatabaseName)
+	if err != nil {
+		return nil, defResponse, err
+	}
+
+	collections := []string{}
+	if input.CollectionName != nil && *input.CollectionName != "" {
+		collections = append(collections, *input.CollectionName)
+	} else {
+		// Views have no indexes of their own.
+		collections, err = DB.ListCollectionNames(ctx, bson.M{"type": "collection"})
+		if err != nil {
+			return nil, defResponse, err
+		}
+	}
+
+	output := defResponse
+	var reclaimable int64
+	for _, name := range collections {
+		collection := DB.Collection(name)
+
+		indexes, err := listIndexes(ctx, collection)
+		if err != nil {
+			return nil, defResponse, err
+		}
+		accesses, started, err := t.indexAccesses(ctx, collection)
+		if err != nil {
+			return nil, defResponse, err
+		}
+
+		for _, index := range indexes {
+			usage := MongoDBIndexUsage{
+				Collection: name,
+				Name:       index.Name,
+				Keys:       index.Keys,
+				Unique:     index.Unique,
+				Accesses:   accesses[index.Name],
+				NeverUsed:  accesses[index.Name] == 0,
+			}
+			if start, ok := started[index.Name]; ok {
+				usage.Since = start.UTC().Format(time.RFC3339)
+				usage.InsufficientHistory = since != nil && start.After(*since)
+			}
+			if index.Size != nil {
+				size := newSize(*index.Size)
+				usage.Size = &size
+			}
+			for _, other := range indexes {
+				// Partial and sparse indexes cannot serve every query of their
+				// prefixes.
+				if other.PartialFilterExpression != nil || other.Sparse {
+					continue
+				}
+				if other.Name != index.Name && len(index.Keys) < len(other.Keys) && isIndexPrefix(index.Keys, other.Keys) {
+					usage.PrefixOf = append(usage.PrefixOf, other.Name)
+				}
+			}
+
+			// The _id index and unique indexes cannot be dropped without
+			// losing a constraint.
+			removable := index.Name != "_id_" && !index.Unique
+			unused := removable && usage.NeverUsed && !usage.InsufficientHistory
+			redundant := removable && len(usage.PrefixOf) > 0
+			if unused {
+				output.UnusedIndexes = append(output.UnusedIndexes, name+"."+index.Name)
+			}
+			if redundant {
+				output.RedundantIndexes = append(output.RedundantIndexes, name+"."+index.Name)
+			}
+			if (unused || redundant) && index.Size != nil {
+				reclaimable += *index.Size
+			}
+
+			output.Indexes = append(output.Indexes, usage)
+		}
+	}
+	output.ReclaimableSize = newSize(reclaimable)
+
+	return nil, output, nil
+}
+
+func (t *NewMongoDBIndexUsageTool) AttachTool(server *mcp.Server) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        t.name(),
+		Description: t.description(),
+	}, t.toolCall)
+}
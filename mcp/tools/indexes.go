@@ -0,0 +1,275 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+type MongoDBIndexKey struct {
+	Field string `json:"field" jsonschema:"The indexed field, use $** for a wildcard index"`
+	Value any    `json:"value" jsonschema:"The index type of the field: 1 or -1 for ascending or descending, or one of text, 2dsphere, 2d or hashed"`
+}
+
+type MongoDBIndexInfo struct {
+	Name                    string            `json:"name" jsonschema:"The name of the index"`
+	Keys                    []MongoDBIndexKey `json:"keys" jsonschema:"The ordered keys of the index"`
+	Unique                  bool              `json:"unique" jsonschema:"Whether the index is unique"`
+	Sparse                  bool              `json:"sparse" jsonschema:"Whether the index is sparse"`
+	Hidden                  bool              `json:"hidden" jsonschema:"Whether the index is hidden from the query planner"`
+	PartialFilterExpression bson.M            `json:"partial_filter_expression,omitempty" jsonschema:"The filter of a partial index"`
+	ExpireAfterSeconds      *int64            `json:"expire_after_seconds,omitempty" jsonschema:"The TTL of the documents in seconds, for TTL indexes"`
+	Collation               bson.M            `json:"collation,omitempty" jsonschema:"The collation of the index"`
+	WildcardProjection      bson.M            `json:"wildcard_projection,omitempty" jsonschema:"The projection of a wildcard index"`
+	Weights                 bson.M            `json:"weights,omitempty" jsonschema:"The field weights of a text index"`
+	DefaultLanguage         string            `json:"default_language,omitempty" jsonschema:"The default language of a text index"`
+	Size                    *int64            `json:"size,omitempty" jsonschema:"The size of the index in bytes"`
+}
+
+type indexDocument struct {
+	Name                    string `bson:"name"`
+	Key                     bson.D `bson:"key"`
+	Unique                  bool   `bson:"unique"`
+	Sparse                  bool   `bson:"sparse"`
+	Hidden                  bool   `bson:"hidden"`
+	PartialFilterExpression bson.M `bson:"partialFilterExpression"`
+	ExpireAfterSeconds      *int64 `bson:"expireAfterSeconds"`
+	Collation               bson.M `bson:"collation"`
+	WildcardProjection      bson.M `bson:"wildcardProjection"`
+	Weights                 bson.M `bson:"weights"`
+	DefaultLanguage         string `bson:"default_language"`
+}
+
+// listIndexes returns the indexes of the collection, with their sizes when
+// the storage statistics of the collection are available.
+func listIndexes(ctx context.Context, collection *mongo.Collection) ([]MongoDBIndexInfo, error) {
+	cursor, err := collection.Indexes().List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var documents []indexDocument
+	if err := cursor.All(ctx, &documents); err != nil {
+		return nil, err
+	}
+
+	// Index sizes are informational, they are left out when $collStats is
+	// not permitted.
+	sizes, err := indexSizes(ctx, collection)
+	if err != nil {
+		sizes = map[string]int64{}
+	}
+
+	indexes := []MongoDBIndexInfo{}
+	for _, document := range documents {
+		index := MongoDBIndexInfo{
+			Name:                    document.Name,
+			Keys:                    []MongoDBIndexKey{},
+			Unique:                  document.Unique,
+			Sparse:                  document.Sparse,
+			Hidden:                  document.Hidden,
+			PartialFilterExpression: document.PartialFilterExpression,
+			ExpireAfterSeconds:      document.ExpireAfterSeconds,
+			Collation:               document.Collation,
+			WildcardProjection:      document.WildcardProjection,
+			Weights:                 document.Weights,
+			DefaultLanguage:         document.DefaultLanguage,
+		}
+		for _, key := range document.Key {
+			index.Keys = append(index.Keys, MongoDBIndexKey{Field: key.Key, Value: key.Value})
+		}
+		if size, ok := sizes[document.Name]; ok {
+			index.Size = &size
+		}
+		indexes = append(indexes, index)
+	}
+
+	return indexes, nil
+}
+
+// indexSizes returns the size in bytes of each index of the collection,
+// summed over all shards.
+func indexSizes(ctx context.Context, collection *mongo.Collection) (map[string]int64, error) {
+	cursor, err := collection.Aggregate(ctx, []bson.M{
+		{"$collStats": bson.M{"storageStats": bson.M{}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var stats []struct {
+		StorageStats struct {
+			IndexSizes map[string]int64 `bson:"indexSizes"`
+		} `bson:"storageStats"`
+	}
+	if err := cursor.All(ctx, &stats); err != nil {
+		return nil, err
+	}
+
+	sizes := map[string]int64{}
+	for _, shard := range stats {
+		for name, size := range shard.StorageStats.IndexSizes {
+			sizes[name] += size
+		}
+	}
+
+	return sizes, nil
+}
+
+// indexKeysDocument converts the ordered keys into an index key document,
+// normalizing JSON numbers to integers.
+func indexKeysDocument(keys []MongoDBIndexKey) (bson.D, error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("At least one index key is required")
+	}
+
+	document := bson.D{}
+	for _, key := range keys {
+		if key.Field == "" {
+			return nil, fmt.Errorf("Index key field cannot be empty")
+		}
+		switch value := key.Value.(type) {
+		case string:
+			document = append(document, bson.E{Key: key.Field, Value: value})
+		case float64:
+			if value != 1 && value != -1 {
+				return nil, fmt.Errorf("Invalid index direction %v for field %s, use 1 or -1", value, key.Field)
+			}
+			document = append(document, bson.E{Key: key.Field, Value: int32(value)})
+		default:
+			return nil, fmt.Errorf("Invalid index type %v for field %s", key.Value, key.Field)
+		}
+	}
+
+	return document, nil
+}
+
+// defaultIndexName returns the name the server gives to an index created
+// without a name, e.g. "name_1_age_-1".
+func defaultIndexName(keys bson.D) string {
+	parts := make([]string, 0, 2*len(keys))
+	for _, key := range keys {
+		parts = append(parts, key.Key, fmt.Sprint(key.Value))
+	}
+	return strings.Join(parts, "_")
+}
+
+// storedIndexKeys returns the keys of the index as stored by the server,
+// which replaces the text fields of a text index by the _fts and _ftsx keys,
+// the text fields being listed in its weights.
+func storedIndexKeys(keys []MongoDBIndexKey) []MongoDBIndexKey {
+	stored := []MongoDBIndexKey{}
+	text := false
+	for _, key := range keys {
+		if key.Value != "text" {
+			stored = append(stored, key)
+			continue
+		}
+		if !text {
+			stored = append(stored, MongoDBIndexKey{Field: "_fts", Value: "text"}, MongoDBIndexKey{Field: "_ftsx", Value: int32(1)})
+			text = true
+		}
+	}
+	return stored
+}
+
+// sameIndexKeys reports whether both key patterns index the same fields in
+// the same order with the same index types.
+func sameIndexKeys(a, b []MongoDBIndexKey) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Field != b[i].Field || !sameIndexValue(a[i].Value, b[i].Value) {
+			return false
+		}
+	}
+	return true
+}
+
+// sameIndexValue compares index values and documents, treating numbers of
+// different types as equal when they hold the same value.
+func sameIndexValue(a, b any) bool {
+	aNumber, aIsNumber := indexNumber(a)
+	bNumber, bIsNumber := indexNumber(b)
+	if aIsNumber || bIsNumber {
+		return aIsNumber && bIsNumber && aNumber == bNumber
+	}
+
+	aDocument, aIsDocument := indexDocumentValue(a)
+	bDocument, bIsDocument := indexDocumentValue(b)
+	if aIsDocument || bIsDocument {
+		if !aIsDocument || !bIsDocument || len(aDocument) != len(bDocument) {
+			return false
+		}
+		for key, value := range aDocument {
+			other, ok := bDocument[key]
+			if !ok || !sameIndexValue(value, other) {
+				return false
+			}
+		}
+		return true
+	}
+
+	aArray, aIsArray := indexArrayValue(a)
+	bArray, bIsArray := indexArrayValue(b)
+	if aIsArray && bIsArray {
+		if len(aArray) != len(bArray) {
+			return false
+		}
+		for i := range aArray {
+			if !sameIndexValue(aArray[i], bArray[i]) {
+				return false
+			}
+		}
+		return true
+	}
+
+	return reflect.DeepEqual(a, b)
+}
+
+func indexDocumentValue(value any) (map[string]any, bool) {
+	switch document := value.(type) {
+	case bson.M:
+		return document, true
+	case map[string]any:
+		return document, true
+	case bson.D:
+		m := map[string]any{}
+		for _, element := range document {
+			m[element.Key] = element.Value
+		}
+		return m, true
+	}
+	return nil, false
+}
+
+func indexArrayValue(value any) ([]any, bool) {
+	switch array := value.(type) {
+	case bson.A:
+		return array, true
+	case []any:
+		return array, true
+	}
+	return nil, false
+}
+
+func indexNumber(value any) (float64, bool) {
+	switch number := value.(type) {
+	case int32:
+		return float64(number), true
+	case int64:
+		return float64(number), true
+	case int:
+		return float64(number), true
+	case float64:
+		return number, true
+	}
+	return 0, false
+}
@@ -2,6 +2,7 @@ package tools
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"go.mongodb.org/mongo-driver/v2/bson"
@@ -13,6 +14,8 @@ type MongoDBInsertManyToolInput struct {
 	DatabaseName   *string  `json:"database_name,omitempty" jsonschema:"Optional name of the database to insert the documents in"`
 	CollectionName string   `json:"collection_name" jsonschema:"Name of the collection to insert the documents in"`
 	Documents      []bson.M `json:"documents" jsonschema:"The documents to insert into the collection"`
+	TransactionID  *string  `json:"transaction_id,omitempty" jsonschema:"Optional id of a transaction returned by the Begin Transaction tool to run the operation in"`
+	MaxTimeMillis  *int64   `json:"max_time_ms,omitempty" jsonschema:"Optional time limit of the operation in milliseconds, defaults to the server timeout of the operation and is capped by the server maximum"`
 }
 
 type MongoDBInsertManyToolOutput struct {
@@ -51,6 +54,15 @@ func (t *NewMongoDBInsertManyTool) toolCall(
 		Result: nil,
 	}
 
+	done, err := t.tool.quotas.acquire(req, operationWrite)
+	if err != nil {
+		return nil, defResponse, err
+	}
+	defer done()
+
+	ctx, cancel := t.tool.timeouts.withTimeout(ctx, operationWrite, input.MaxTimeMillis)
+	defer cancel()
+
 	DB, err := t.tool.Database(input.DatabaseName)
 	if err != nil {
 		return nil, defResponse, err
@@ -58,6 +70,22 @@ func (t *NewMongoDBInsertManyTool) toolCall(
 
 	collection := DB.Collection(input.CollectionName)
 
+	scope, err := t.tool.scope.resolve(req.Session, collectionNamespace(collection))
+	if err != nil {
+		return nil, defResponse, err
+	}
+	for i, document := range input.Documents {
+		if err := scopeDocument(scope, document, fmt.Sprintf("documents.%d", i)); err != nil {
+			return nil, defResponse, err
+		}
+	}
+
+	ctx, release, err := t.tool.transactions.join(ctx, req.Session, input.TransactionID)
+	if err != nil {
+		return nil, defResponse, err
+	}
+	defer release()
+
 	opts := options.InsertMany()
 
 	res, err := collection.InsertMany(ctx, input.Documents, opts)
@@ -13,6 +13,8 @@ type MongoDBInsertOneToolInput struct {
 	DatabaseName   *string `json:"database_name,omitempty" jsonschema:"Optional name of the database to insert the document in"`
 	CollectionName string  `json:"collection_name" jsonschema:"Name of the collection to insert the document in"`
 	Document       bson.M  `json:"document" jsonschema:"The document to insert into the collection"`
+	TransactionID  *string `json:"transaction_id,omitempty" jsonschema:"Optional id of a transaction returned by the Begin Transaction tool to run the operation in"`
+	MaxTimeMillis  *int64  `json:"max_time_ms,omitempty" jsonschema:"Optional time limit of the operation in milliseconds, defaults to the server timeout of the operation and is capped by the server maximum"`
 }
 
 type MongoDBInsertOneToolOutput struct {
@@ -51,6 +53,15 @@ func (t *NewMongoDBInsertOneTool) toolCall(
 		Result: nil,
 	}
 
+	done, err := t.tool.quotas.acquire(req, operationWrite)
+	if err != nil {
+		return nil, defResponse, err
+	}
+	defer done()
+
+	ctx, cancel := t.tool.timeouts.withTimeout(ctx, operationWrite, input.MaxTimeMillis)
+	defer cancel()
+
 	DB, err := t.tool.Database(input.DatabaseName)
 	if err != nil {
 		return nil, defResponse, err
@@ -58,6 +69,20 @@ func (t *NewMongoDBInsertOneTool) toolCall(
 
 	collection := DB.Collection(input.CollectionName)
 
+	scope, err := t.tool.scope.resolve(req.Session, collectionNamespace(collection))
+	if err != nil {
+		return nil, defResponse, err
+	}
+	if err := scopeDocument(scope, input.Document, "document"); err != nil {
+		return nil, defResponse, err
+	}
+
+	ctx, release, err := t.tool.transactions.join(ctx, req.Session, input.TransactionID)
+	if err != nil {
+		return nil, defResponse, err
+	}
+	defer release()
+
 	opts := options.InsertOne()
 
 	res, err := collection.InsertOne(ctx, input.Document, opts)
@@ -5,14 +5,31 @@ import (
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
 )
 
 type MongoDBListCollectionsToolInput struct {
-	DatabaseName *string `json:"database_name,omitempty" jsonschema:"Optional name of the database to list collections from"`
+	DatabaseName   *string `json:"database_name,omitempty" jsonschema:"Optional name of the database to list collections from"`
+	NameFilter     *string `json:"name_filter,omitempty" jsonschema:"Optional exact collection name to filter the collections by"`
+	NameRegex      *string `json:"name_regex,omitempty" jsonschema:"Optional regular expression to filter the collection names by"`
+	IncludeDetails *bool   `json:"include_details,omitempty" jsonschema:"Optional whether to include the type, options, document estimate and index count of each collection, defaults to false"`
+	MaxTimeMillis  *int64  `json:"max_time_ms,omitempty" jsonschema:"Optional time limit of the operation in milliseconds, defaults to the server timeout of the operation and is capped by the server maximum"`
+}
+
+type MongoDBCollectionInfo struct {
+	Name                   string   `json:"name" jsonschema:"The name of the collection"`
+	Type                   string   `json:"type" jsonschema:"The type of the collection, one of collection, view or timeseries"`
+	ViewOn                 string   `json:"view_on,omitempty" jsonschema:"The source collection of the view"`
+	ViewPipeline           []bson.M `json:"view_pipeline,omitempty" jsonschema:"The aggregation pipeline of the view"`
+	HasValidator           bool     `json:"has_validator" jsonschema:"Whether the collection has a schema validator"`
+	Capped                 bool     `json:"capped" jsonschema:"Whether the collection is capped"`
+	EstimatedDocumentCount *int64   `json:"estimated_document_count,omitempty" jsonschema:"The estimated number of documents in the collection"`
+	IndexCount             *int     `json:"index_count,omitempty" jsonschema:"The number of indexes on the collection"`
 }
 
 type MongoDBListCollectionsToolOutput struct {
-	Collections []string `json:"collections" jsonschema:"The list of collections in the database"`
+	Collections []string                `json:"collections" jsonschema:"The list of collections in the database"`
+	Details     []MongoDBCollectionInfo `json:"details,omitempty" jsonschema:"The details of the collections, only set when include_details is true"`
 }
 
 type MongoDBListCollectionsTool struct {
@@ -31,7 +48,30 @@ func (t *MongoDBListCollectionsTool) name() string {
 
 func (t *MongoDBListCollectionsTool) description() string {
 	return "# List collections in MongoDB.\n\n" +
-		"This tool can be used to list all collections in a MongoDB database.\n\n"
+		"This tool can be used to list all collections in a MongoDB database.\n\n" +
+		"Use `name_filter` or `name_regex` to narrow down databases with many collections, " +
+		"and `include_details` to get the type, view pipeline, validator, capped status, " +
+		"estimated document count and index count of each collection.\n\n"
+}
+
+func (t *MongoDBListCollectionsTool) filter(input MongoDBListCollectionsToolInput) bson.M {
+	conditions := []bson.M{}
+	if input.NameFilter != nil && *input.NameFilter != "" {
+		conditions = append(conditions, bson.M{"name": *input.NameFilter})
+	}
+	if input.NameRegex != nil && *input.NameRegex != "" {
+		conditions = append(conditions, bson.M{"name": bson.M{"$regex": *input.NameRegex}})
+	}
+
+	switch len(conditions) {
+	case 0:
+		return bson.M{}
+	case 1:
+		return conditions[0]
+	default:
+		// Both narrow the names, a collection has to match each of them.
+		return bson.M{"$and": conditions}
+	}
 }
 
 func (t *MongoDBListCollectionsTool) toolCall(
@@ -46,20 +86,125 @@ func (t *MongoDBListCollectionsTool) toolCall(
 	defResponse := MongoDBListCollectionsToolOutput{
 		Collections: []string{},
 	}
+
+	done, err := t.tool.quotas.acquire(req, operationRead)
+	if err != nil {
+		return nil, defResponse, err
+	}
+	defer done()
+
 	DB, err := t.tool.Database(input.DatabaseName)
 	if err != nil {
 		return nil, defResponse, err
 	}
-	collections, err := DB.ListCollectionNames(ctx, bson.M{})
+
+	ctx, cancel := t.tool.timeouts.withTimeout(ctx, operationRead, input.MaxTimeMillis)
+	defer cancel()
+
+	if input.IncludeDetails == nil || !*input.IncludeDetails {
+		var collections []string
+		retries, err := t.tool.retry.do(ctx, func(ctx context.Context) error {
+			var err error
+			collections, err = DB.ListCollectionNames(ctx, t.filter(input))
+			return err
+		})
+		if err != nil {
+			return nil, defResponse, err
+		}
+
+		return retryResult(retries), MongoDBListCollectionsToolOutput{
+			Collections: collections,
+		}, nil
+	}
+
+	var output MongoDBListCollectionsToolOutput
+	retries, err := t.tool.retry.do(ctx, func(ctx context.Context) error {
+		var err error
+		output, err = t.details(ctx, DB, input)
+		return err
+	})
 	if err != nil {
 		return nil, defResponse, err
 	}
 
+	return retryResult(retries), output, nil
+}
+
+// details lists the collections with their type, options, document estimate
+// and index count.
+func (t *MongoDBListCollectionsTool) details(
+	ctx context.Context,
+	DB *mongo.Database,
+	input MongoDBListCollectionsToolInput,
+) (MongoDBListCollectionsToolOutput, error) {
+	defResponse := MongoDBListCollectionsToolOutput{
+		Collections: []string{},
+	}
+
+	cursor, err := DB.ListCollections(ctx, t.filter(input))
+	if err != nil {
+		return defResponse, err
+	}
+	defer cursor.Close(ctx)
+
+	var specs []struct {
+		Name    string `bson:"name"`
+		Type    string `bson:"type"`
+		Options struct {
+			ViewOn     string   `bson:"viewOn"`
+			Pipeline   []bson.M `bson:"pipeline"`
+			Validator  bson.M   `bson:"validator"`
+			Capped     bool     `bson:"capped"`
+			TimeSeries bson.M   `bson:"timeseries"`
+		} `bson:"options"`
+	}
+	if err := cursor.All(ctx, &specs); err != nil {
+		return defResponse, err
+	}
+
 	output := MongoDBListCollectionsToolOutput{
-		Collections: collections,
+		Collections: []string{},
+		Details:     []MongoDBCollectionInfo{},
+	}
+	for _, spec := range specs {
+		info := MongoDBCollectionInfo{
+			Name:         spec.Name,
+			Type:         spec.Type,
+			ViewOn:       spec.Options.ViewOn,
+			ViewPipeline: spec.Options.Pipeline,
+			HasValidator: len(spec.Options.Validator) > 0,
+			Capped:       spec.Options.Capped,
+		}
+		if info.Type == "" {
+			info.Type = "collection"
+		}
+		if spec.Options.TimeSeries != nil {
+			info.Type = "timeseries"
+		}
+
+		// Views have neither documents of their own nor indexes.
+		if info.Type != "view" {
+			collection := DB.Collection(spec.Name)
+
+			count, err := collection.EstimatedDocumentCount(ctx)
+			if err != nil {
+				return defResponse, err
+			}
+			info.EstimatedDocumentCount = &count
+
+			indexes, err := collection.Indexes().ListSpecifications(ctx)
+			if err != nil {
+				return defResponse, err
+			}
+			indexCount := len(indexes)
+			info.IndexCount = &indexCount
+		}
+
+		output.Collections = append(output.Collections, spec.Name)
+		output.Details = append(output.Details, info)
 	}
 
-	return nil, output, nil
+	return output, nil
 }
 
 func (t *MongoDBListCollectionsTool) AttachTool(server *mcp.Server) {
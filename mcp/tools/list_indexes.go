@@ -0,0 +1,83 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type MongoDBListIndexesToolInput struct {
+	DatabaseName   *string `json:"database_name,omitempty" jsonschema:"Optional name of the database of the collection"`
+	CollectionName string  `json:"collection_name" jsonschema:"Name of the collection to list the indexes of"`
+	MaxTimeMillis  *int64  `json:"max_time_ms,omitempty" jsonschema:"Optional time limit of the operation in milliseconds, defaults to the server timeout of the operation and is capped by the server maximum"`
+}
+
+type MongoDBListIndexesToolOutput struct {
+	Indexes []MongoDBIndexInfo `json:"indexes" jsonschema:"The indexes of the collection"`
+}
+
+type NewMongoDBListIndexesTool struct {
+	tool *Tool
+}
+
+func (t *Tool) NewMongoDBListIndexesTool() *NewMongoDBListIndexesTool {
+	return &NewMongoDBListIndexesTool{
+		tool: t,
+	}
+}
+
+func (t *NewMongoDBListIndexesTool) name() string {
+	return "[MongoDB] List Indexes Tool"
+}
+
+func (t *NewMongoDBListIndexesTool) description() string {
+	return "# List indexes in MongoDB.\n\n" +
+		"This tool can be used to list the indexes of a MongoDB collection with their keys, " +
+		"unique, sparse, partial filter, TTL, collation and size.\n\n"
+}
+
+func (t *NewMongoDBListIndexesTool) toolCall(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	input MongoDBListIndexesToolInput,
+) (
+	*mcp.CallToolResult,
+	MongoDBListIndexesToolOutput,
+	error,
+) {
+	defResponse := MongoDBListIndexesToolOutput{
+		Indexes: []MongoDBIndexInfo{},
+	}
+
+	done, err := t.tool.quotas.acquire(req, operationRead)
+	if err != nil {
+		return nil, defResponse, err
+	}
+	defer done()
+
+	ctx, cancel := t.tool.timeouts.withTimeout(ctx, operationRead, input.MaxTimeMillis)
+	defer cancel()
+
+	DB, err := t.tool.Database(input.DatabaseName)
+	if err != nil {
+		return nil, defResponse, err
+	}
+
+	collection := DB.Collection(input.CollectionName)
+
+	indexes, err := listIndexes(ctx, collection)
+	if err != nil {
+		return nil, defResponse, err
+	}
+
+	return nil, MongoDBListIndexesToolOutput{
+		Indexes: indexes,
+	}, nil
+}
+
+func (t *NewMongoDBListIndexesTool) AttachTool(server *mcp.Server) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        t.name(),
+		Description: t.description(),
+	}, t.toolCall)
+}
@@ -4,7 +4,10 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"path"
+	"strconv"
 	"strings"
+	"time"
 
 	"go.mongodb.org/mongo-driver/v2/mongo"
 	"go.mongodb.org/mongo-driver/v2/mongo/options"
@@ -13,16 +16,29 @@ import (
 type Tool struct {
 	ReadOnly        bool
 	AllowAggregates bool
+	AllowAdmin      bool
 
 	connectionString string
 	database         string
 	client           *mongo.Client
+	schemaCache      *schemaCache
+	guard            *queryGuard
+	resumeTokens     *resumeTokenStore
+	transactions     *transactionManager
+	retry            *retryPolicy
+	timeouts         *queryTimeouts
+	pipelines        *pipelinePolicy
+	filters          *filterPolicy
+	redaction        *redactionPolicy
+	scope            *scopePolicy
+	quotas           *quotaPolicy
 }
 
 func NewTool() *Tool {
 	tool := &Tool{
 		ReadOnly:        false,
 		AllowAggregates: false,
+		AllowAdmin:      false,
 	}
 
 	tool.validateArgs()
@@ -35,6 +51,36 @@ func (t *Tool) validateArgs() {
 	dbURL := os.Getenv("DB_URL")
 	ReadOnly := strings.ToLower(strings.TrimSpace(os.Getenv("READ_ONLY")))
 	AllowAggregates := strings.ToLower(strings.TrimSpace(os.Getenv("ALLOW_AGGREGATES")))
+	AllowAdmin := strings.ToLower(strings.TrimSpace(os.Getenv("ALLOW_ADMIN")))
+	schemaCacheTTL := strings.TrimSpace(os.Getenv("SCHEMA_CACHE_TTL"))
+	queryGuardMode := strings.TrimSpace(os.Getenv("QUERY_GUARD"))
+	queryGuardMinCollectionSize := strings.TrimSpace(os.Getenv("QUERY_GUARD_MIN_COLLECTION_SIZE"))
+	queryGuardMaxDocsExamined := strings.TrimSpace(os.Getenv("QUERY_GUARD_MAX_DOCS_EXAMINED"))
+	resumeTokenFile := strings.TrimSpace(os.Getenv("RESUME_TOKEN_FILE"))
+	transactionTimeout := strings.TrimSpace(os.Getenv("TRANSACTION_TIMEOUT"))
+	readRetryAttempts := strings.TrimSpace(os.Getenv("READ_RETRY_ATTEMPTS"))
+	readRetryBackoff := strings.TrimSpace(os.Getenv("READ_RETRY_BACKOFF"))
+	readRetryMaxBackoff := strings.TrimSpace(os.Getenv("READ_RETRY_MAX_BACKOFF"))
+	queryTimeouts := map[string]string{
+		operationRead:      strings.TrimSpace(os.Getenv("QUERY_TIMEOUT_READ")),
+		operationWrite:     strings.TrimSpace(os.Getenv("QUERY_TIMEOUT_WRITE")),
+		operationAggregate: strings.TrimSpace(os.Getenv("QUERY_TIMEOUT_AGGREGATE")),
+		operationAdmin:     strings.TrimSpace(os.Getenv("QUERY_TIMEOUT_ADMIN")),
+	}
+	queryTimeoutMax := strings.TrimSpace(os.Getenv("QUERY_TIMEOUT_MAX"))
+	aggregateAllowedStages := strings.TrimSpace(os.Getenv("AGGREGATE_ALLOWED_STAGES"))
+	aggregateAllowedOperators := strings.TrimSpace(os.Getenv("AGGREGATE_ALLOWED_OPERATORS"))
+	aggregateDeniedDatabases := strings.TrimSpace(os.Getenv("AGGREGATE_DENIED_DATABASES"))
+	filterDeniedOperators, filterDeniedOperatorsSet := os.LookupEnv("FILTER_DENIED_OPERATORS")
+	filterMaxDepth := strings.TrimSpace(os.Getenv("FILTER_MAX_DEPTH"))
+	filterMaxInSize := strings.TrimSpace(os.Getenv("FILTER_MAX_IN_SIZE"))
+	redactionPolicyFile := strings.TrimSpace(os.Getenv("REDACTION_POLICY_FILE"))
+	scopeFilter := strings.TrimSpace(os.Getenv("SCOPE_FILTER"))
+	scopeSessionMetaKey := strings.TrimSpace(os.Getenv("SCOPE_SESSION_META_KEY"))
+	scopeCollections := strings.TrimSpace(os.Getenv("SCOPE_COLLECTIONS"))
+	rateLimits := strings.TrimSpace(os.Getenv("RATE_LIMITS"))
+	concurrencyLimits := strings.TrimSpace(os.Getenv("CONCURRENCY_LIMITS"))
+	quotaPrincipalMetaKey := strings.TrimSpace(os.Getenv("QUOTA_PRINCIPAL_META_KEY"))
 
 	if ReadOnly == "true" || ReadOnly == "1" {
 		t.ReadOnly = true
@@ -44,6 +90,177 @@ func (t *Tool) validateArgs() {
 		t.AllowAggregates = true
 	}
 
+	if AllowAdmin == "true" || AllowAdmin == "1" {
+		t.AllowAdmin = true
+	}
+
+	t.schemaCache = newSchemaCache(5 * time.Minute)
+	if schemaCacheTTL != "" {
+		ttl, err := time.ParseDuration(schemaCacheTTL)
+		if err != nil {
+			log.Fatalf("invalid SCHEMA_CACHE_TTL: %s", err.Error())
+		}
+		t.schemaCache = newSchemaCache(ttl)
+	}
+
+	mode, err := parseQueryGuardMode(queryGuardMode)
+	if err != nil {
+		log.Fatalf("invalid QUERY_GUARD: %s", err.Error())
+	}
+	t.guard = &queryGuard{
+		mode:              mode,
+		minCollectionSize: 10000,
+	}
+	if queryGuardMinCollectionSize != "" {
+		size, err := strconv.ParseInt(queryGuardMinCollectionSize, 10, 64)
+		if err != nil {
+			log.Fatalf("invalid QUERY_GUARD_MIN_COLLECTION_SIZE: %s", err.Error())
+		}
+		t.guard.minCollectionSize = size
+	}
+	if queryGuardMaxDocsExamined != "" {
+		limit, err := strconv.ParseInt(queryGuardMaxDocsExamined, 10, 64)
+		if err != nil {
+			log.Fatalf("invalid QUERY_GUARD_MAX_DOCS_EXAMINED: %s", err.Error())
+		}
+		t.guard.maxDocsExamined = limit
+	}
+
+	t.resumeTokens, err = newResumeTokenStore(resumeTokenFile)
+	if err != nil {
+		log.Fatalf("invalid RESUME_TOKEN_FILE: %s", err.Error())
+	}
+
+	t.transactions = newTransactionManager(60 * time.Second)
+	if transactionTimeout != "" {
+		timeout, err := time.ParseDuration(transactionTimeout)
+		if err != nil {
+			log.Fatalf("invalid TRANSACTION_TIMEOUT: %s", err.Error())
+		}
+		if timeout <= 0 {
+			log.Fatal("invalid TRANSACTION_TIMEOUT: the timeout must be positive")
+		}
+		t.transactions = newTransactionManager(timeout)
+	}
+
+	t.retry = &retryPolicy{
+		maxAttempts:    3,
+		initialBackoff: 100 * time.Millisecond,
+		maxBackoff:     2 * time.Second,
+	}
+	if readRetryAttempts != "" {
+		attempts, err := strconv.Atoi(readRetryAttempts)
+		if err != nil {
+			log.Fatalf("invalid READ_RETRY_ATTEMPTS: %s", err.Error())
+		}
+		if attempts < 1 {
+			log.Fatal("invalid READ_RETRY_ATTEMPTS: at least one attempt is required")
+		}
+		t.retry.maxAttempts = attempts
+	}
+	if readRetryBackoff != "" {
+		backoff, err := time.ParseDuration(readRetryBackoff)
+		if err != nil {
+			log.Fatalf("invalid READ_RETRY_BACKOFF: %s", err.Error())
+		}
+		t.retry.initialBackoff = backoff
+	}
+	if readRetryMaxBackoff != "" {
+		backoff, err := time.ParseDuration(readRetryMaxBackoff)
+		if err != nil {
+			log.Fatalf("invalid READ_RETRY_MAX_BACKOFF: %s", err.Error())
+		}
+		t.retry.maxBackoff = backoff
+	}
+
+	t.timeouts = newQueryTimeouts()
+	for category, value := range queryTimeouts {
+		if value == "" {
+			continue
+		}
+		timeout, err := time.ParseDuration(value)
+		if err != nil {
+			log.Fatalf("invalid QUERY_TIMEOUT_%s: %s", strings.ToUpper(category), err.Error())
+		}
+		t.timeouts.defaults[category] = timeout
+	}
+	if queryTimeoutMax != "" {
+		timeout, err := time.ParseDuration(queryTimeoutMax)
+		if err != nil {
+			log.Fatalf("invalid QUERY_TIMEOUT_MAX: %s", err.Error())
+		}
+		t.timeouts.max = timeout
+	}
+
+	t.pipelines = newPipelinePolicy(t.ReadOnly)
+	if aggregateAllowedStages != "" {
+		t.pipelines.allowedStages = parseNameList(aggregateAllowedStages)
+	}
+	t.pipelines.allowedOperators = parseNameList(aggregateAllowedOperators)
+	t.pipelines.deniedDatabases = parseNameList(aggregateDeniedDatabases)
+
+	t.filters = newFilterPolicy()
+	if filterDeniedOperatorsSet {
+		t.filters.deniedOperators = parseNameList(filterDeniedOperators)
+	}
+	if filterMaxDepth != "" {
+		depth, err := strconv.Atoi(filterMaxDepth)
+		if err != nil {
+			log.Fatalf("invalid FILTER_MAX_DEPTH: %s", err.Error())
+		}
+		t.filters.maxDepth = depth
+	}
+	if filterMaxInSize != "" {
+		size, err := strconv.Atoi(filterMaxInSize)
+		if err != nil {
+			log.Fatalf("invalid FILTER_MAX_IN_SIZE: %s", err.Error())
+		}
+		t.filters.maxInSize = size
+	}
+
+	redaction, err := newRedactionPolicy(redactionPolicyFile)
+	if err != nil {
+		log.Fatalf("invalid REDACTION_POLICY_FILE: %s", err.Error())
+	}
+	t.redaction = redaction
+
+	t.scope = &scopePolicy{sessionKey: scopeSessionMetaKey}
+	if scopeFilter != "" {
+		filter, err := parseScopeFilter([]byte(scopeFilter))
+		if err != nil {
+			log.Fatalf("invalid SCOPE_FILTER: %s", err.Error())
+		}
+		t.scope.filter = filter
+	}
+	for collection := range parseNameList(scopeCollections) {
+		if _, err := path.Match(collection, ""); err != nil {
+			log.Fatalf("invalid SCOPE_COLLECTIONS: %s", err.Error())
+		}
+		t.scope.collections = append(t.scope.collections, collection)
+	}
+
+	t.quotas = newQuotaPolicy()
+	t.quotas.principalKey = quotaPrincipalMetaKey
+	err = parseQuotaLimits(rateLimits, func(name, limit string) error {
+		rate, err := parseRateLimit(limit)
+		t.quotas.rates[name] = rate
+		return err
+	})
+	if err != nil {
+		log.Fatalf("invalid RATE_LIMITS: %s", err.Error())
+	}
+	err = parseQuotaLimits(concurrencyLimits, func(name, limit string) error {
+		count, err := strconv.Atoi(limit)
+		if err == nil && count <= 0 {
+			err = fmt.Errorf("the limit of %s must be a positive integer", name)
+		}
+		t.quotas.concurrency[name] = count
+		return err
+	})
+	if err != nil {
+		log.Fatalf("invalid CONCURRENCY_LIMITS: %s", err.Error())
+	}
+
 	if dbURL == "" {
 		log.Fatal("missing required environment variable: DB_URL")
 	}
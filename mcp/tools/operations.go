@@ -0,0 +1,124 @@
+package tools
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// progressInterval is the minimum interval between two progress
+// notifications of a tool call.
+const progressInterval = 500 * time.Millisecond
+
+// killTimeout bounds the commands killing the operations of a cancelled tool
+// call.
+const killTimeout = 10 * time.Second
+
+// progressReporter sends the MCP progress notifications of a tool call. It
+// does nothing when the call has no progress token.
+type progressReporter struct {
+	session *mcp.ServerSession
+	token   any
+	last    time.Time
+}
+
+func newProgressReporter(req *mcp.CallToolRequest) *progressReporter {
+	reporter := &progressReporter{}
+	if req != nil && req.Params != nil {
+		reporter.session = req.Session
+		reporter.token = req.Params.GetProgressToken()
+	}
+	return reporter
+}
+
+// report notifies the processed and total counts, a zero total meaning it is
+// unknown. Notifications are throttled, except the one completing the total.
+func (p *progressReporter) report(ctx context.Context, processed, total int64, message string) {
+	if p.session == nil || p.token == nil {
+		return
+	}
+	if time.Since(p.last) < progressInterval && (total == 0 || processed < total) {
+		return
+	}
+	p.last = time.Now()
+
+	err := p.session.NotifyProgress(ctx, &mcp.ProgressNotificationParams{
+		ProgressToken: p.token,
+		Progress:      float64(processed),
+		Total:         float64(total),
+		Message:       message,
+	})
+	if err != nil {
+		log.Printf("failed to send progress notification: %s", err.Error())
+	}
+}
+
+// newOperationComment returns a comment identifying the server operations of
+// a tool call.
+func newOperationComment() string {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return "mongodb-mcp"
+	}
+	return "mongodb-mcp-" + hex.EncodeToString(id)
+}
+
+// currentOperations returns the server operations matching the filter.
+func (t *Tool) currentOperations(ctx context.Context, filter bson.D) ([]bson.M, error) {
+	command := append(bson.D{{Key: "currentOp", Value: 1}}, filter...)
+
+	var result struct {
+		InProgress []bson.M `bson:"inprog"`
+	}
+	if err := t.client.Database("admin").RunCommand(ctx, command).Decode(&result); err != nil {
+		return nil, err
+	}
+	return result.InProgress, nil
+}
+
+// killOnCancel kills the server operations matching the filter once the
+// context is cancelled or times out, as a server operation outlives the
+// connection the driver closes, e.g. a long update or an index build. The
+// returned function stops the watch and must be called once the operation
+// is done. Operations in a transaction are stopped by aborting it instead.
+func (t *Tool) killOnCancel(ctx context.Context, filter bson.D) func() bool {
+	if mongo.SessionFromContext(ctx) != nil {
+		return func() bool { return false }
+	}
+
+	return context.AfterFunc(ctx, func() {
+		ctx, cancel := context.WithTimeout(context.Background(), killTimeout)
+		defer cancel()
+
+		operations, err := t.currentOperations(ctx, filter)
+		if err != nil {
+			log.Printf("failed to list the operations to kill: %s", err.Error())
+			return
+		}
+		for _, operation := range operations {
+			opid, ok := operation["opid"]
+			if !ok {
+				continue
+			}
+			err := t.client.Database("admin").RunCommand(ctx, bson.D{
+				{Key: "killOp", Value: 1},
+				{Key: "op", Value: opid},
+			}).Err()
+			if err != nil {
+				log.Printf("failed to kill operation %v: %s", opid, err.Error())
+			}
+		}
+	})
+}
+
+// commentFilter returns the currentOp filter of the operations tagged with
+// the comment.
+func commentFilter(comment string) bson.D {
+	return bson.D{{Key: "command.comment", Value: comment}}
+}
@@ -0,0 +1,371 @@
+package tools
+
+import (
+	"math"
+	"net"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const (
+	defaultPIIMinConfidence = 0.5
+	maxPIIExamples          = 3
+)
+
+// The categories of personal data detected by the PII scanner.
+const (
+	piiEmail      = "email"
+	piiPhone      = "phone"
+	piiIBAN       = "iban"
+	piiCreditCard = "credit_card"
+	piiNationalID = "national_id"
+	piiIPAddress  = "ip_address"
+	piiName       = "name"
+)
+
+var (
+	piiEmailPattern      = regexp.MustCompile(`^[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}$`)
+	piiPhonePattern      = regexp.MustCompile(`^\+?\(?[0-9][0-9 ().-]{5,20}[0-9]$`)
+	piiDashPhonePattern  = regexp.MustCompile(`^([0-9]{3}-)?[0-9]{3}-[0-9]{4}$`)
+	piiDatePattern       = regexp.MustCompile(`^[0-9]{4}-[0-9]{2}-[0-9]{2}`)
+	piiIBANPattern       = regexp.MustCompile(`^[A-Z]{2}[0-9]{2}[A-Z0-9]{11,30}$`)
+	piiSSNPattern        = regexp.MustCompile(`^([0-9]{3})-([0-9]{2})-([0-9]{4})$`)
+	piiIdentifierPattern = regexp.MustCompile(`^[A-Z0-9][A-Z0-9 -]{4,16}[A-Z0-9]$`)
+	piiNamePattern       = regexp.MustCompile(`^\p{Lu}[\p{L}'.-]*( \p{Lu}[\p{L}'.-]*){0,3}$`)
+)
+
+// piiDetector classifies the values of a category. Hinted reports whether
+// the name of the field suggests the category, weak formats such as plain
+// digits or names are only matched for hinted fields.
+type piiDetector struct {
+	category string
+	// weight is the confidence of a field whose every value matches.
+	weight float64
+	hint   func(name string) bool
+	match  func(value string, hinted bool) bool
+}
+
+var piiDetectors = []piiDetector{
+	{
+		category: piiEmail,
+		weight:   1,
+		hint:     func(name string) bool { return strings.Contains(name, "mail") },
+		match: func(value string, hinted bool) bool {
+			return piiEmailPattern.MatchString(value)
+		},
+	},
+	{
+		category: piiIBAN,
+		weight:   1,
+		hint:     func(name string) bool { return strings.Contains(name, "iban") },
+		match: func(value string, hinted bool) bool {
+			return validIBAN(value)
+		},
+	},
+	{
+		category: piiCreditCard,
+		weight:   0.95,
+		hint:     func(name string) bool { return strings.Contains(name, "card") },
+		match: func(value string, hinted bool) bool {
+			return validCardNumber(value)
+		},
+	},
+	{
+		category: piiNationalID,
+		weight:   0.85,
+		hint: func(name string) bool {
+			for _, hint := range []string{"ssn", "nationalid", "socialsecurity", "passport", "taxid"} {
+				if strings.Contains(name, hint) {
+					return true
+				}
+			}
+			return false
+		},
+		match: func(value string, hinted bool) bool {
+			return validSSN(value) || (hinted && piiIdentifierPattern.MatchString(value) && strings.ContainsAny(value, "0123456789"))
+		},
+	},
+	{
+		category: piiIPAddress,
+		weight:   0.9,
+		hint: func(name string) bool {
+			return name == "ip" || strings.Contains(name, "ipaddr") || strings.Contains(name, "remoteaddr")
+		},
+		match: func(value string, hinted bool) bool {
+			return strings.ContainsAny(value, ".:") && net.ParseIP(value) != nil
+		},
+	},
+	{
+		category: piiPhone,
+		weight:   0.8,
+		hint: func(name string) bool {
+			for _, hint := range []string{"phone", "mobile", "tel", "fax"} {
+				if strings.Contains(name, hint) {
+					return true
+				}
+			}
+			return false
+		},
+		match: func(value string, hinted bool) bool {
+			if !piiPhonePattern.MatchString(value) || piiDatePattern.MatchString(value) {
+				return false
+			}
+			digits := onlyDigits(value)
+			if len(digits) < 7 || len(digits) > 15 {
+				return false
+			}
+			// Plain numbers are only phone numbers in a phone field, and
+			// numbers grouped by dashes only in the 555-123-4567 format, as
+			// dashes also separate dates and identifiers.
+			return hinted || strings.HasPrefix(value, "+") || strings.ContainsAny(value, " ()") || piiDashPhonePattern.MatchString(value)
+		},
+	},
+	{
+		category: piiName,
+		weight:   0.8,
+		hint: func(name string) bool {
+			switch name {
+			case "name", "firstname", "lastname", "fullname", "surname", "givenname", "familyname",
+				"middlename", "contactname", "customername", "displayname":
+				return true
+			}
+			return false
+		},
+		match: func(value string, hinted bool) bool {
+			return hinted && piiNamePattern.MatchString(value)
+		},
+	},
+}
+
+type MongoDBPIIField struct {
+	Path       string   `json:"path" jsonschema:"The dot notation path of the field"`
+	Category   string   `json:"category" jsonschema:"The kind of personal data detected, one of email, phone, iban, credit_card, national_id, ip_address or name"`
+	Confidence float64  `json:"confidence" jsonschema:"The confidence that the field holds this kind of personal data, from 0 to 1"`
+	Matches    int      `json:"matches" jsonschema:"The number of sampled values matching the category"`
+	Values     int      `json:"values" jsonschema:"The number of sampled values of the field"`
+	Examples   []string `json:"examples,omitempty" jsonschema:"Masked examples of the matching values"`
+}
+
+// piiField accumulates the sampled values of a field.
+type piiField struct {
+	hints    map[string]bool
+	values   int
+	matches  map[string]int
+	examples map[string][]string
+}
+
+// piiScan classifies the fields of sampled documents.
+type piiScan struct {
+	fields map[string]*piiField
+}
+
+func newPIIScan() *piiScan {
+	return &piiScan{fields: map[string]*piiField{}}
+}
+
+// normalizedFieldName returns the last segment of the path in lower case
+// without separators, e.g. "contact.Phone_Number" gives "phonenumber".
+func normalizedFieldName(path string) string {
+	name := path[strings.LastIndex(path, ".")+1:]
+	return strings.NewReplacer("_", "", "-", "", " ", "").Replace(strings.ToLower(name))
+}
+
+func onlyDigits(value string) string {
+	var digits strings.Builder
+	for _, r := range value {
+		if r >= '0' && r <= '9' {
+			digits.WriteRune(r)
+		}
+	}
+	return digits.String()
+}
+
+// validCardNumber reports whether the value is a 13 to 19 digits card
+// number, optionally grouped by spaces or dashes, passing the Luhn check.
+func validCardNumber(value string) bool {
+	if strings.Trim(value, "0123456789 -") != "" {
+		return false
+	}
+	digits := onlyDigits(value)
+	if len(digits) < 13 || len(digits) > 19 || strings.Trim(digits, "0") == "" {
+		return false
+	}
+
+	sum := 0
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		digit := int(digits[i] - '0')
+		if double {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// validIBAN reports whether the value is an IBAN passing the mod 97 check.
+func validIBAN(value string) bool {
+	iban := strings.ToUpper(strings.ReplaceAll(value, " ", ""))
+	if !piiIBANPattern.MatchString(iban) {
+		return false
+	}
+
+	remainder := 0
+	for _, r := range iban[4:] + iban[:4] {
+		if r >= 'A' && r <= 'Z' {
+			remainder = (remainder*100 + int(r-'A') + 10) % 97
+		} else {
+			remainder = (remainder*10 + int(r-'0')) % 97
+		}
+	}
+	return remainder == 1
+}
+
+// validSSN reports whether the value is a US social security number, whose
+// area, group and serial numbers are never all zeros.
+func validSSN(value string) bool {
+	parts := piiSSNPattern.FindStringSubmatch(value)
+	if parts == nil {
+		return false
+	}
+	area, _ := strconv.Atoi(parts[1])
+	return area != 0 && area != 666 && area < 900 && parts[2] != "00" && parts[3] != "0000"
+}
+
+// addDocument adds the string and integer values of the document, the
+// elements of arrays being added to the path of the array.
+func (s *piiScan) addDocument(document bson.M) {
+	for key, value := range document {
+		s.addValue(key, value)
+	}
+}
+
+func (s *piiScan) addValue(path string, value any) {
+	if document, ok := pipelineDocument(value); ok {
+		for key, nested := range document {
+			s.addValue(path+"."+key, nested)
+		}
+		return
+	}
+	if values, ok := pipelineStages(value); ok {
+		for _, nested := range values {
+			s.addValue(path, nested)
+		}
+		return
+	}
+
+	var str string
+	switch v := value.(type) {
+	case string:
+		str = strings.TrimSpace(v)
+	case int64:
+		str = strconv.FormatInt(v, 10)
+	case int32:
+		str = strconv.FormatInt(int64(v), 10)
+	default:
+		return
+	}
+	if str == "" {
+		return
+	}
+
+	field, ok := s.fields[path]
+	if !ok {
+		field = &piiField{
+			hints:    map[string]bool{},
+			matches:  map[string]int{},
+			examples: map[string][]string{},
+		}
+		name := normalizedFieldName(path)
+		for _, detector := range piiDetectors {
+			field.hints[detector.category] = detector.hint(name)
+		}
+		s.fields[path] = field
+	}
+
+	field.values++
+	for _, detector := range piiDetectors {
+		if !detector.match(str, field.hints[detector.category]) {
+			continue
+		}
+		field.matches[detector.category]++
+		if examples := field.examples[detector.category]; len(examples) < maxPIIExamples {
+			field.examples[detector.category] = append(examples, maskString(str))
+		}
+	}
+}
+
+// results returns the most likely category of each field with a confidence
+// of at least minConfidence, by decreasing confidence. The confidence is the
+// ratio of matching values weighted by the category, raised for the fields
+// whose name suggests the category.
+func (s *piiScan) results(minConfidence float64) []MongoDBPIIField {
+	results := []MongoDBPIIField{}
+	for path, field := range s.fields {
+		var best *MongoDBPIIField
+		for _, detector := range piiDetectors {
+			matches := field.matches[detector.category]
+			if matches == 0 {
+				continue
+			}
+			confidence := detector.weight * float64(matches) / float64(field.values)
+			if field.hints[detector.category] {
+				confidence = math.Min(1, confidence+0.15)
+			}
+			confidence = math.Round(confidence*100) / 100
+			if best != nil && best.Confidence >= confidence {
+				continue
+			}
+			best = &MongoDBPIIField{
+				Path:       path,
+				Category:   detector.category,
+				Confidence: confidence,
+				Matches:    matches,
+				Values:     field.values,
+				Examples:   field.examples[detector.category],
+			}
+		}
+		if best != nil && best.Confidence >= minConfidence {
+			results = append(results, *best)
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Confidence != results[j].Confidence {
+			return results[i].Confidence > results[j].Confidence
+		}
+		return results[i].Path < results[j].Path
+	})
+	return results
+}
+
+// piiRedactionPolicy returns the redaction rules hiding the detected fields of
+// the namespace with the mode, ready to be used in REDACTION_POLICY_FILE.
+func piiRedactionPolicy(namespace string, fields []MongoDBPIIField, mode string) *redactionPolicy {
+	policy := &redactionPolicy{Rules: []*redactionRule{}}
+	if len(fields) == 0 {
+		return policy
+	}
+
+	paths := []string{}
+	for _, field := range fields {
+		paths = append(paths, field.Path)
+	}
+	sort.Strings(paths)
+
+	policy.Rules = append(policy.Rules, &redactionRule{
+		Namespaces: []string{namespace},
+		Fields:     paths,
+		Values:     []string{},
+		Mode:       mode,
+	})
+	return policy
+}
@@ -0,0 +1,294 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// defaultAllowedStages are the stages allowed in aggregation pipelines when
+// AGGREGATE_ALLOWED_STAGES is not set.
+var defaultAllowedStages = []string{
+	"$addFields", "$bucket", "$bucketAuto", "$changeStream", "$collStats", "$count",
+	"$densify", "$documents", "$facet", "$fill", "$geoNear", "$graphLookup", "$group",
+	"$indexStats", "$limit", "$lookup", "$match", "$merge", "$out", "$project",
+	"$redact", "$replaceRoot", "$replaceWith", "$sample", "$search", "$searchMeta",
+	"$set", "$setWindowFields", "$skip", "$sort", "$sortByCount", "$unionWith",
+	"$unset", "$unwind", "$vectorSearch",
+}
+
+// restrictedOperators run server-side JavaScript, they are refused unless
+// listed in AGGREGATE_ALLOWED_OPERATORS.
+var restrictedOperators = []string{"$accumulator", "$function", "$where"}
+
+// writeStages are the stages writing the result of a pipeline to a
+// collection.
+var writeStages = []string{"$merge", "$out"}
+
+// pipelinePolicy inspects aggregation pipelines before they run, refusing
+// the stages and operators that are not allowed, the write stages in read
+// only mode and the references to denied databases.
+type pipelinePolicy struct {
+	readOnly         bool
+	allowedStages    map[string]bool
+	allowedOperators map[string]bool
+	deniedDatabases  map[string]bool
+}
+
+type pipelinePolicyError struct {
+	Reason string `json:"reason"`
+	Stage  string `json:"stage"`
+	Path   string `json:"path"`
+}
+
+func (e *pipelinePolicyError) message() string {
+	return "Pipeline refused: " + e.Reason + "."
+}
+
+func (e *pipelinePolicyError) Error() string {
+	message := e.message()
+
+	details, err := json.Marshal(e)
+	if err != nil {
+		return message
+	}
+	return message + "\n\n" + string(details)
+}
+
+// parseNameList parses a comma separated list of names, such as stages or
+// databases.
+func parseNameList(value string) map[string]bool {
+	names := map[string]bool{}
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		if name != "" {
+			names[name] = true
+		}
+	}
+	return names
+}
+
+func newPipelinePolicy(readOnly bool) *pipelinePolicy {
+	policy := &pipelinePolicy{
+		readOnly:         readOnly,
+		allowedStages:    map[string]bool{},
+		allowedOperators: map[string]bool{},
+		deniedDatabases:  map[string]bool{},
+	}
+	for _, stage := range defaultAllowedStages {
+		policy.allowedStages[stage] = true
+	}
+	return policy
+}
+
+// pipelineDocument returns the value as a document, for the documents
+// decoded from JSON or built by the tools.
+func pipelineDocument(value any) (map[string]any, bool) {
+	switch document := value.(type) {
+	case bson.M:
+		return document, true
+	case map[string]any:
+		return document, true
+	case bson.D:
+		converted := make(map[string]any, len(document))
+		for _, element := range document {
+			converted[element.Key] = element.Value
+		}
+		return converted, true
+	}
+	return nil, false
+}
+
+// pipelineStages returns the value as a list of stages, for the sub
+// pipelines of $lookup, $facet and $unionWith.
+func pipelineStages(value any) ([]any, bool) {
+	switch stages := value.(type) {
+	case []any:
+		return stages, true
+	case []bson.M:
+		converted := make([]any, 0, len(stages))
+		for _, stage := range stages {
+			converted = append(converted, stage)
+		}
+		return converted, true
+	case bson.A:
+		return stages, true
+	}
+	return nil, false
+}
+
+// check returns a *pipelinePolicyError when the pipeline is not allowed.
+func (p *pipelinePolicy) check(pipeline []bson.M) error {
+	if p == nil {
+		return nil
+	}
+
+	stages := make([]any, 0, len(pipeline))
+	for _, stage := range pipeline {
+		stages = append(stages, stage)
+	}
+	return p.checkStages(stages, "pipeline")
+}
+
+// checkUpdate returns a *pipelinePolicyError when an update pipeline uses a
+// restricted operator, the documents of update operators are not checked.
+// The path names the update in the tool input.
+func (p *pipelinePolicy) checkUpdate(update any, path string) error {
+	stages, ok := pipelineStages(update)
+	if p == nil || !ok {
+		return nil
+	}
+
+	for i, value := range stages {
+		stage, _ := pipelineDocument(value)
+		for name, spec := range stage {
+			if err := p.checkOperators(name, spec, fmt.Sprintf("%s.%d.%s", path, i, name)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (p *pipelinePolicy) checkStages(stages []any, path string) error {
+	for i, value := range stages {
+		stagePath := fmt.Sprintf("%s.%d", path, i)
+
+		stage, ok := pipelineDocument(value)
+		if !ok || len(stage) != 1 {
+			return &pipelinePolicyError{
+				Reason: "each stage must be a document with a single stage operator",
+				Path:   stagePath,
+			}
+		}
+
+		for name, spec := range stage {
+			if err := p.checkStage(name, spec, stagePath); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (p *pipelinePolicy) checkStage(name string, spec any, path string) error {
+	if !p.allowedStages[name] {
+		return &pipelinePolicyError{
+			Reason: fmt.Sprintf("the %s stage is not allowed", name),
+			Stage:  name,
+			Path:   path,
+		}
+	}
+	for _, stage := range writeStages {
+		if name == stage && p.readOnly {
+			return &pipelinePolicyError{
+				Reason: fmt.Sprintf("the %s stage writes data, which is not allowed in read only mode", name),
+				Stage:  name,
+				Path:   path,
+			}
+		}
+	}
+
+	document, _ := pipelineDocument(spec)
+	switch name {
+	case "$lookup", "$graphLookup", "$unionWith":
+		// $lookup and $graphLookup reference {db, coll} in from, $unionWith
+		// in its specification.
+		namespace, namespacePath := document["from"], path+"."+name+".from"
+		if name == "$unionWith" {
+			namespace, namespacePath = spec, path+"."+name
+		}
+		if err := p.checkNamespace(name, namespace, namespacePath); err != nil {
+			return err
+		}
+		if stages, ok := pipelineStages(document["pipeline"]); ok {
+			if err := p.checkStages(stages, path+"."+name+".pipeline"); err != nil {
+				return err
+			}
+		}
+	case "$facet":
+		for facet, value := range document {
+			stages, ok := pipelineStages(value)
+			if !ok {
+				continue
+			}
+			if err := p.checkStages(stages, path+".$facet."+facet); err != nil {
+				return err
+			}
+		}
+	case "$out":
+		if err := p.checkNamespace(name, spec, path+".$out"); err != nil {
+			return err
+		}
+	case "$merge":
+		if err := p.checkNamespace(name, document["into"], path+".$merge.into"); err != nil {
+			return err
+		}
+	}
+
+	return p.checkOperators(name, spec, path+"."+name)
+}
+
+// checkNamespace refuses the references of a stage to a collection of a
+// denied database, given as {db, coll}.
+func (p *pipelinePolicy) checkNamespace(stage string, value any, path string) error {
+	document, ok := pipelineDocument(value)
+	if !ok {
+		return nil
+	}
+	database, ok := document["db"].(string)
+	if !ok || !p.deniedDatabases[database] {
+		return nil
+	}
+
+	return &pipelinePolicyError{
+		Reason: fmt.Sprintf("the %s stage references the database %s, which is denied", stage, database),
+		Stage:  stage,
+		Path:   path,
+	}
+}
+
+// checkOperators refuses the restricted operators used anywhere in the
+// specification of a stage.
+func (p *pipelinePolicy) checkOperators(stage string, value any, path string) error {
+	if document, ok := pipelineDocument(value); ok {
+		for key, nested := range document {
+			if p.restricted(key) {
+				return &pipelinePolicyError{
+					Reason: fmt.Sprintf("the %s operator runs JavaScript on the server, which is not allowed", key),
+					Stage:  stage,
+					Path:   path + "." + key,
+				}
+			}
+			if err := p.checkOperators(stage, nested, path+"."+key); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if values, ok := pipelineStages(value); ok {
+		for i, nested := range values {
+			if err := p.checkOperators(stage, nested, fmt.Sprintf("%s.%d", path, i)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (p *pipelinePolicy) restricted(operator string) bool {
+	if p.allowedOperators[operator] {
+		return false
+	}
+	for _, restricted := range restrictedOperators {
+		if operator == restricted {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,282 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+const (
+	queryGuardOff     = "off"
+	queryGuardBlock   = "block"
+	queryGuardConfirm = "confirm"
+)
+
+// queryGuard explains queries before they run and refuses the ones that scan
+// large collections or examine too many documents.
+type queryGuard struct {
+	mode              string
+	minCollectionSize int64
+	maxDocsExamined   int64
+}
+
+type queryGuardError struct {
+	Reason                string                 `json:"reason"`
+	Summary               *MongoDBExplainSummary `json:"plan_summary"`
+	EstimatedDocsExamined *int64                 `json:"estimated_docs_examined,omitempty"`
+	SuggestedIndex        []MongoDBIndexKey      `json:"suggested_index,omitempty"`
+	Confirmable           bool                   `json:"-"`
+}
+
+func (e *queryGuardError) message() string {
+	message := "Query refused by the query cost guard: " + e.Reason + "."
+	if e.Confirmable {
+		message += " Narrow the filter, create the suggested index, or set allow_collection_scan to true to run it anyway."
+	} else {
+		message += " Narrow the filter or create the suggested index."
+	}
+	return message
+}
+
+func (e *queryGuardError) Error() string {
+	message := e.message()
+
+	details, err := json.Marshal(e)
+	if err != nil {
+		return message
+	}
+	return message + "\n\n" + string(details)
+}
+
+// parseQueryGuardMode validates the QUERY_GUARD setting.
+func parseQueryGuardMode(mode string) (string, error) {
+	switch strings.ToLower(mode) {
+	case "", queryGuardOff, "false", "0":
+		return queryGuardOff, nil
+	case queryGuardBlock, "true", "1":
+		return queryGuardBlock, nil
+	case queryGuardConfirm:
+		return queryGuardConfirm, nil
+	}
+	return "", fmt.Errorf("use off, block or confirm")
+}
+
+// check explains the query and returns a *queryGuardError when it is too
+// expensive to run. allowScan is the confirmation given by the caller, which
+// is only honoured in confirm mode.
+func (g *queryGuard) check(ctx context.Context, collection *mongo.Collection, query explainQuery, allowScan *bool) error {
+	if g == nil || g.mode == queryGuardOff {
+		return nil
+	}
+	if g.mode == queryGuardConfirm && allowScan != nil && *allowScan {
+		return nil
+	}
+
+	// The plan is not run, running it would cost as much as the query. The
+	// examined documents are estimated from the winning plan instead.
+	raw, summary, err := explain(ctx, collection, query, explainQueryPlanner)
+	if err != nil {
+		return err
+	}
+
+	reason := ""
+	var estimated *int64
+	if summary.IsCollectionScan || fullIndexScan(raw) {
+		// Views and collections that cannot be counted are treated as large.
+		size, err := collection.EstimatedDocumentCount(ctx)
+		switch {
+		case summary.IsCollectionScan && (err != nil || size > g.minCollectionSize):
+			reason = fmt.Sprintf("the plan scans the whole collection of more than %d documents", g.minCollectionSize)
+		case err == nil && g.maxDocsExamined > 0 && size > g.maxDocsExamined:
+			estimated = &size
+			reason = fmt.Sprintf("the plan examines an estimated %d documents, more than the limit of %d", size, g.maxDocsExamined)
+		}
+	}
+	if reason == "" && g.maxDocsExamined > 0 && !summary.IsCollectionScan {
+		if size, ok := g.boundedScanSize(ctx, collection, query, raw, summary); ok && size > g.maxDocsExamined {
+			estimated = &size
+			reason = fmt.Sprintf("the plan examines an estimated %d documents or more, more than the limit of %d", size, g.maxDocsExamined)
+		}
+	}
+	if reason == "" {
+		return nil
+	}
+
+	guardErr := &queryGuardError{
+		Reason:                reason,
+		Summary:               summary,
+		EstimatedDocsExamined: estimated,
+		Confirmable:           g.mode == queryGuardConfirm,
+	}
+	if query.operation != "aggregate" {
+		guardErr.SuggestedIndex = suggestIndex(query.filter, query.sort)
+	} else if len(query.pipeline) > 0 {
+		if match, ok := indexDocumentValue(query.pipeline[0]["$match"]); ok {
+			guardErr.SuggestedIndex = suggestIndex(match, nil)
+		}
+	}
+	if len(guardErr.SuggestedIndex) == 0 {
+		guardErr.SuggestedIndex = nil
+	}
+
+	return guardErr
+}
+
+// fullIndexScan reports whether the winning plan scans a whole index, from
+// MinKey to MaxKey on every field, examining every document of the
+// collection like a collection scan. Bounded index scans are estimated by
+// boundedScanSize.
+func fullIndexScan(raw bson.Raw) bool {
+	for _, planner := range findRawDocuments(raw, "queryPlanner") {
+		plan, ok := planner.Lookup("winningPlan").DocumentOK()
+		if !ok {
+			continue
+		}
+		for _, bounds := range findRawDocuments(plan, "indexBounds") {
+			if unboundedIndex(bounds) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// unboundedIndex reports whether the index bounds of a plan stage cover
+// every key of the index.
+func unboundedIndex(bounds bson.Raw) bool {
+	elements, err := bounds.Elements()
+	if err != nil || len(elements) == 0 {
+		return false
+	}
+	for _, element := range elements {
+		intervals, ok := element.Value().ArrayOK()
+		if !ok {
+			return false
+		}
+		values, _ := intervals.Values()
+		if len(values) != 1 {
+			return false
+		}
+		switch interval, _ := values[0].StringValueOK(); interval {
+		case "[MinKey, MaxKey]", "[MaxKey, MinKey]":
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// boundedScanSize estimates the documents examined by a plan scanning index
+// bounds, counting the documents matching the conditions of the filter on
+// the fields of each scanned index. The count only reads the keys of the
+// index and stops past maxDocsExamined, so it never costs as much as the
+// query. It reports false when the scans cannot be estimated, such as the
+// scans bounded by $or branches.
+func (g *queryGuard) boundedScanSize(ctx context.Context, collection *mongo.Collection, query explainQuery, raw bson.Raw, summary *MongoDBExplainSummary) (int64, bool) {
+	filter := query.filter
+	if query.operation == "aggregate" {
+		if len(query.pipeline) == 0 {
+			return 0, false
+		}
+		filter, _ = indexDocumentValue(query.pipeline[0]["$match"])
+	}
+	if len(filter) == 0 {
+		return 0, false
+	}
+
+	var total int64
+	estimated := false
+	for _, planner := range findRawDocuments(raw, "queryPlanner") {
+		plan, ok := planner.Lookup("winningPlan").DocumentOK()
+		if !ok {
+			continue
+		}
+		for _, scan := range indexScans(plan) {
+			keyPattern, ok := scan.Lookup("keyPattern").DocumentOK()
+			if !ok {
+				continue
+			}
+			conditions, complete := indexedConditions(filter, keyPattern)
+			if len(conditions) == 0 {
+				return 0, false
+			}
+
+			opts := options.Count().SetHint(keyPattern).SetLimit(g.maxDocsExamined + 1)
+			count, err := collection.CountDocuments(ctx, bson.M{"$and": conditions}, opts)
+			if err != nil {
+				// Views and hidden indexes cannot be hinted, the scan is
+				// left to the other checks.
+				return 0, false
+			}
+
+			// Without a blocking sort and other conditions, the scan stops
+			// at the limit of the query.
+			if query.limit > 0 && complete && !slices.Contains(summary.Stages, "SORT") {
+				count = min(count, query.skip+query.limit)
+			}
+			total += count
+			estimated = true
+		}
+	}
+	return total, estimated
+}
+
+// indexScans returns the index scan stages of a winning plan.
+func indexScans(plan bson.Raw) []bson.Raw {
+	scans := []bson.Raw{}
+	if _, ok := plan.Lookup("indexBounds").DocumentOK(); ok {
+		scans = append(scans, plan)
+	}
+	for _, key := range []string{"queryPlan", "inputStage", "winningPlan"} {
+		if child, ok := plan.Lookup(key).DocumentOK(); ok {
+			scans = append(scans, indexScans(child)...)
+		}
+	}
+	for _, key := range []string{"inputStages", "shards"} {
+		if children, ok := plan.Lookup(key).ArrayOK(); ok {
+			values, _ := children.Values()
+			for _, value := range values {
+				if child, ok := value.DocumentOK(); ok {
+					scans = append(scans, indexScans(child)...)
+				}
+			}
+		}
+	}
+	return scans
+}
+
+// indexedConditions returns the conditions of the filter, and of its $and
+// clauses, on the fields of the key pattern, reporting whether they are all
+// of the conditions of the filter.
+func indexedConditions(filter bson.M, keyPattern bson.Raw) (bson.A, bool) {
+	conditions := bson.A{}
+	complete := true
+	for field, value := range filter {
+		if field == "$and" {
+			clauses, _ := pipelineStages(value)
+			for _, clause := range clauses {
+				document, ok := indexDocumentValue(clause)
+				if !ok {
+					complete = false
+					continue
+				}
+				nested, nestedComplete := indexedConditions(document, keyPattern)
+				conditions = append(conditions, nested...)
+				complete = complete && nestedComplete
+			}
+			continue
+		}
+		if _, err := keyPattern.LookupErr(field); err != nil || strings.HasPrefix(field, "$") {
+			complete = false
+			continue
+		}
+		conditions = append(conditions, bson.M{field: value})
+	}
+	return conditions, complete
+}
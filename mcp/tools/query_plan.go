@@ -0,0 +1,218 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+const (
+	explainQueryPlanner      = "queryPlanner"
+	explainExecutionStats    = "executionStats"
+	explainAllPlansExecution = "allPlansExecution"
+)
+
+type MongoDBExplainSummary struct {
+	Stages              []string `json:"stages" jsonschema:"The stages of the winning plan from the root down, followed by the aggregation stages"`
+	IndexesUsed         []string `json:"indexes_used" jsonschema:"The names of the indexes used by the winning plan"`
+	IsCollectionScan    bool     `json:"is_collection_scan" jsonschema:"Whether the winning plan scans the whole collection"`
+	DocsExamined        *int64   `json:"docs_examined,omitempty" jsonschema:"The number of documents examined, only with executionStats verbosity"`
+	KeysExamined        *int64   `json:"keys_examined,omitempty" jsonschema:"The number of index keys examined, only with executionStats verbosity"`
+	DocsReturned        *int64   `json:"docs_returned,omitempty" jsonschema:"The number of documents returned by the query stage, only with executionStats verbosity"`
+	ExecutionTimeMillis *int64   `json:"execution_time_millis,omitempty" jsonschema:"The execution time in milliseconds, only with executionStats verbosity"`
+}
+
+// explainQuery describes the query or aggregation to explain.
+type explainQuery struct {
+	operation  string
+	filter     bson.M
+	sort       bson.D
+	projection bson.M
+	limit      int64
+	skip       int64
+	pipeline   []bson.M
+}
+
+func (q explainQuery) command(collection string) (bson.D, error) {
+	switch q.operation {
+	case "find":
+		command := bson.D{{Key: "find", Value: collection}, {Key: "filter", Value: nonNilFilter(q.filter)}}
+		if len(q.sort) > 0 {
+			command = append(command, bson.E{Key: "sort", Value: q.sort})
+		}
+		if len(q.projection) > 0 {
+			command = append(command, bson.E{Key: "projection", Value: q.projection})
+		}
+		if q.limit > 0 {
+			command = append(command, bson.E{Key: "limit", Value: q.limit})
+		}
+		if q.skip > 0 {
+			command = append(command, bson.E{Key: "skip", Value: q.skip})
+		}
+		return command, nil
+	case "count":
+		command := bson.D{{Key: "count", Value: collection}, {Key: "query", Value: nonNilFilter(q.filter)}}
+		if q.limit > 0 {
+			command = append(command, bson.E{Key: "limit", Value: q.limit})
+		}
+		if q.skip > 0 {
+			command = append(command, bson.E{Key: "skip", Value: q.skip})
+		}
+		return command, nil
+	case "aggregate":
+		pipeline := q.pipeline
+		if pipeline == nil {
+			pipeline = []bson.M{}
+		}
+		return bson.D{
+			{Key: "aggregate", Value: collection},
+			{Key: "pipeline", Value: pipeline},
+			{Key: "cursor", Value: bson.M{}},
+		}, nil
+	}
+	return nil, fmt.Errorf("Unsupported operation to explain: %s, use find, count or aggregate", q.operation)
+}
+
+func nonNilFilter(filter bson.M) bson.M {
+	if filter == nil {
+		return bson.M{}
+	}
+	return filter
+}
+
+// explain runs the explain command for the query at the given verbosity and
+// summarizes the winning plan.
+func explain(ctx context.Context, collection *mongo.Collection, query explainQuery, verbosity string) (bson.Raw, *MongoDBExplainSummary, error) {
+	switch verbosity {
+	case "":
+		verbosity = explainExecutionStats
+	case explainQueryPlanner, explainExecutionStats, explainAllPlansExecution:
+	default:
+		return nil, nil, fmt.Errorf("Unsupported explain verbosity: %s, use queryPlanner, executionStats or allPlansExecution", verbosity)
+	}
+
+	command, err := query.command(collection.Name())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	raw, err := collection.Database().RunCommand(ctx, bson.D{
+		{Key: "explain", Value: command},
+		{Key: "verbosity", Value: verbosity},
+	}).Raw()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return raw, summarizeExplain(raw), nil
+}
+
+func summarizeExplain(raw bson.Raw) *MongoDBExplainSummary {
+	summary := &MongoDBExplainSummary{
+		Stages:      []string{},
+		IndexesUsed: []string{},
+	}
+
+	for _, planner := range findRawDocuments(raw, "queryPlanner") {
+		if plan, ok := planner.Lookup("winningPlan").DocumentOK(); ok {
+			summary.walkPlan(plan)
+		}
+	}
+
+	if stats := findRawDocuments(raw, "executionStats"); len(stats) > 0 {
+		summary.DocsExamined = rawInt64(stats[0], "totalDocsExamined")
+		summary.KeysExamined = rawInt64(stats[0], "totalKeysExamined")
+		summary.DocsReturned = rawInt64(stats[0], "nReturned")
+		summary.ExecutionTimeMillis = rawInt64(stats[0], "executionTimeMillis")
+	}
+
+	if stages, ok := raw.Lookup("stages").ArrayOK(); ok {
+		values, _ := stages.Values()
+		for _, value := range values {
+			if stage, ok := value.DocumentOK(); ok {
+				if element, err := stage.IndexErr(0); err == nil {
+					summary.Stages = append(summary.Stages, element.Key())
+				}
+			}
+		}
+	}
+
+	return summary
+}
+
+func (s *MongoDBExplainSummary) walkPlan(plan bson.Raw) {
+	if stage, ok := plan.Lookup("stage").StringValueOK(); ok {
+		s.Stages = append(s.Stages, stage)
+		if stage == "COLLSCAN" {
+			s.IsCollectionScan = true
+		}
+	}
+	if index, ok := plan.Lookup("indexName").StringValueOK(); ok {
+		s.IndexesUsed = append(s.IndexesUsed, index)
+	}
+
+	// Slot based plans nest the classic plan under queryPlan, sharded plans
+	// list one winning plan per shard.
+	for _, key := range []string{"queryPlan", "inputStage", "winningPlan"} {
+		if child, ok := plan.Lookup(key).DocumentOK(); ok {
+			s.walkPlan(child)
+		}
+	}
+	for _, key := range []string{"inputStages", "shards"} {
+		if children, ok := plan.Lookup(key).ArrayOK(); ok {
+			values, _ := children.Values()
+			for _, value := range values {
+				if child, ok := value.DocumentOK(); ok {
+					s.walkPlan(child)
+				}
+			}
+		}
+	}
+}
+
+// findRawDocuments returns all documents stored under the key, searching
+// the document and its nested documents and arrays depth first.
+func findRawDocuments(document bson.Raw, key string) []bson.Raw {
+	found := []bson.Raw{}
+
+	elements, err := document.Elements()
+	if err != nil {
+		return found
+	}
+	for _, element := range elements {
+		value := element.Value()
+		if element.Key() == key {
+			if child, ok := value.DocumentOK(); ok {
+				found = append(found, child)
+				continue
+			}
+		}
+		switch value.Type {
+		case bson.TypeEmbeddedDocument:
+			found = append(found, findRawDocuments(value.Document(), key)...)
+		case bson.TypeArray:
+			values, _ := value.Array().Values()
+			for _, item := range values {
+				if child, ok := item.DocumentOK(); ok {
+					found = append(found, findRawDocuments(child, key)...)
+				}
+			}
+		}
+	}
+
+	return found
+}
+
+func rawInt64(document bson.Raw, key string) *int64 {
+	value, err := document.LookupErr(key)
+	if err != nil {
+		return nil
+	}
+	number, ok := value.AsInt64OK()
+	if !ok {
+		return nil
+	}
+	return &number
+}
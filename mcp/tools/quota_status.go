@@ -0,0 +1,59 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type MongoDBQuotaStatusToolInput struct{}
+
+type MongoDBQuotaStatusToolOutput struct {
+	Principal string              `json:"principal" jsonschema:"The principal whose calls are limited, the session or the value of the configured _meta key of its initialize request"`
+	Quotas    []MongoDBQuotaUsage `json:"quotas" jsonschema:"The usage of the configured limits, empty when the calls are not limited"`
+}
+
+type NewMongoDBQuotaStatusTool struct {
+	tool *Tool
+}
+
+func (t *Tool) NewMongoDBQuotaStatusTool() *NewMongoDBQuotaStatusTool {
+	return &NewMongoDBQuotaStatusTool{
+		tool: t,
+	}
+}
+
+func (t *NewMongoDBQuotaStatusTool) name() string {
+	return "[MongoDB] Quota Status Tool"
+}
+
+func (t *NewMongoDBQuotaStatusTool) description() string {
+	return "# Report the usage of the rate and concurrency limits of the tool calls.\n\n" +
+		"This tool reports, for the global limit, the limit of this principal and the limits of its categories of " +
+		"operations (read, write, aggregate and admin), the allowed rate, the calls available right away, the time " +
+		"until the next call is allowed and the calls in progress. Calling it does not count against the limits.\n\n"
+}
+
+func (t *NewMongoDBQuotaStatusTool) toolCall(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	input MongoDBQuotaStatusToolInput,
+) (
+	*mcp.CallToolResult,
+	MongoDBQuotaStatusToolOutput,
+	error,
+) {
+	principal, quotas := t.tool.quotas.usage(req.Session)
+
+	return nil, MongoDBQuotaStatusToolOutput{
+		Principal: principal,
+		Quotas:    quotas,
+	}, nil
+}
+
+func (t *NewMongoDBQuotaStatusTool) AttachTool(server *mcp.Server) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        t.name(),
+		Description: t.description(),
+	}, t.toolCall)
+}
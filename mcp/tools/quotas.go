@@ -0,0 +1,368 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// The limits of the quotas besides the categories of operations: the calls
+// of every principal, and the calls of a principal.
+const (
+	limitGlobal  = "global"
+	limitSession = "session"
+)
+
+// quotaSweepInterval is the minimum time between two evictions of the idle
+// quotas.
+const quotaSweepInterval = time.Minute
+
+// concurrencyRetryAfter is the delay suggested to the calls refused by a
+// concurrency limit, the end of the running operations being unknown.
+const concurrencyRetryAfter = 250 * time.Millisecond
+
+// quotaLimits are the names of the configurable limits, in the order of the
+// status tool.
+var quotaLimits = []string{limitGlobal, limitSession, operationRead, operationWrite, operationAggregate, operationAdmin}
+
+// rateLimit allows count calls per period, in bursts of up to count calls.
+type rateLimit struct {
+	count  int
+	period time.Duration
+}
+
+func (l rateLimit) String() string {
+	switch l.period {
+	case time.Second:
+		return fmt.Sprintf("%d/s", l.count)
+	case time.Minute:
+		return fmt.Sprintf("%d/m", l.count)
+	case time.Hour:
+		return fmt.Sprintf("%d/h", l.count)
+	}
+	return fmt.Sprintf("%d/%s", l.count, l.period)
+}
+
+// parseRateLimit parses a rate such as "20/s", "600/m", "5000/h" or
+// "10/500ms".
+func parseRateLimit(value string) (rateLimit, error) {
+	count, period, ok := strings.Cut(value, "/")
+	if !ok {
+		return rateLimit{}, fmt.Errorf("invalid rate %q, use count/period such as 20/s", value)
+	}
+
+	limit := rateLimit{}
+	var err error
+	if limit.count, err = strconv.Atoi(strings.TrimSpace(count)); err != nil || limit.count <= 0 {
+		return rateLimit{}, fmt.Errorf("invalid rate %q, the count must be a positive integer", value)
+	}
+	switch period = strings.TrimSpace(period); period {
+	case "s":
+		limit.period = time.Second
+	case "m":
+		limit.period = time.Minute
+	case "h":
+		limit.period = time.Hour
+	default:
+		if limit.period, err = time.ParseDuration(period); err != nil || limit.period <= 0 {
+			return rateLimit{}, fmt.Errorf("invalid rate %q, the period must be s, m, h or a duration", value)
+		}
+	}
+	return limit, nil
+}
+
+// parseQuotaLimits parses a comma separated list of limit=value pairs, the
+// limits being global, session or a category of operations.
+func parseQuotaLimits(value string, parse func(name, limit string) error) error {
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		name, limit, ok := strings.Cut(pair, "=")
+		if !ok {
+			return fmt.Errorf("invalid limit %q, use name=value", pair)
+		}
+		name = strings.TrimSpace(name)
+		if !containsName(quotaLimits, name) {
+			return fmt.Errorf("unknown limit %q, use global, session, read, write, aggregate or admin", name)
+		}
+		if err := parse(name, strings.TrimSpace(limit)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tokenBucket refills its capacity of calls at a constant rate.
+type tokenBucket struct {
+	limit  rateLimit
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(limit rateLimit, now time.Time) *tokenBucket {
+	return &tokenBucket{limit: limit, tokens: float64(limit.count), last: now}
+}
+
+func (b *tokenBucket) refill(now time.Time) {
+	rate := float64(b.limit.count) / float64(b.limit.period)
+	b.tokens = math.Min(float64(b.limit.count), b.tokens+rate*float64(now.Sub(b.last)))
+	b.last = now
+}
+
+// retryAfter returns the time until a call is allowed.
+func (b *tokenBucket) retryAfter() time.Duration {
+	if b.tokens >= 1 {
+		return 0
+	}
+	rate := float64(b.limit.count) / float64(b.limit.period)
+	return time.Duration(math.Ceil((1 - b.tokens) / rate))
+}
+
+// quota is the usage of a limit by a principal, or by every principal for
+// the global limit.
+type quota struct {
+	key    string
+	bucket *tokenBucket
+	active int
+}
+
+// idle reports whether the quota is in the state of a new one, so that it
+// can be evicted without changing the limits.
+func (q *quota) idle(now time.Time) bool {
+	if q.active > 0 {
+		return false
+	}
+	if q.bucket == nil {
+		return true
+	}
+	q.bucket.refill(now)
+	return q.bucket.tokens >= float64(q.bucket.limit.count)
+}
+
+type rateLimitError struct {
+	Reason           string `json:"reason"`
+	Limit            string `json:"limit"`
+	RetryAfterMillis int64  `json:"retry_after_ms"`
+}
+
+func (e *rateLimitError) message() string {
+	return fmt.Sprintf("Rate limited: %s, retry after %d ms.", e.Reason, e.RetryAfterMillis)
+}
+
+func (e *rateLimitError) Error() string {
+	message := e.message()
+
+	details, err := json.Marshal(e)
+	if err != nil {
+		return message
+	}
+	return message + "\n\n" + string(details)
+}
+
+// quotaPolicy limits the rate and the concurrency of the tool calls,
+// globally, per principal and per category of operations of a principal. A
+// principal is a session, or the sessions sharing the same value of
+// principalKey in the _meta of their initialize request.
+type quotaPolicy struct {
+	mu           sync.Mutex
+	rates        map[string]rateLimit
+	concurrency  map[string]int
+	principalKey string
+	quotas       map[string]*quota
+	lastSweep    time.Time
+}
+
+func newQuotaPolicy() *quotaPolicy {
+	return &quotaPolicy{
+		rates:       map[string]rateLimit{},
+		concurrency: map[string]int{},
+		quotas:      map[string]*quota{},
+	}
+}
+
+// principal returns the principal of the session.
+func (p *quotaPolicy) principal(session *mcp.ServerSession) string {
+	if session == nil {
+		return ""
+	}
+	if p.principalKey != "" {
+		if params := session.InitializeParams(); params != nil {
+			if principal, ok := params.Meta[p.principalKey].(string); ok && principal != "" {
+				return principal
+			}
+		}
+	}
+	if id := session.ID(); id != "" {
+		return "session " + id
+	}
+	return fmt.Sprintf("session %p", session)
+}
+
+// quotaKey returns the key of the quota of the limit for the principal.
+func quotaKey(limit, principal string) string {
+	if limit == limitGlobal {
+		return limitGlobal
+	}
+	return limit + " " + principal
+}
+
+// quota returns the quota of the limit for the principal, nil when the
+// limit is not configured. A new quota is only stored when store is set, the
+// quotas being evicted once idle. The caller holds p.mu.
+func (p *quotaPolicy) quota(limit, principal string, now time.Time, store bool) *quota {
+	rate, limited := p.rates[limit]
+	if _, ok := p.concurrency[limit]; !ok && !limited {
+		return nil
+	}
+
+	key := quotaKey(limit, principal)
+	q, ok := p.quotas[key]
+	if !ok {
+		q = &quota{key: key}
+		if limited {
+			q.bucket = newTokenBucket(rate, now)
+		}
+		if store {
+			p.quotas[key] = q
+		}
+	}
+	if q.bucket != nil {
+		q.bucket.refill(now)
+	}
+	return q
+}
+
+// sweep evicts the idle quotas, at most once per quotaSweepInterval so that
+// the quotas of the ended sessions do not pile up. The caller holds p.mu.
+func (p *quotaPolicy) sweep(now time.Time) {
+	if now.Sub(p.lastSweep) < quotaSweepInterval {
+		return
+	}
+	p.lastSweep = now
+
+	for key, q := range p.quotas {
+		if q.idle(now) {
+			delete(p.quotas, key)
+		}
+	}
+}
+
+// acquire admits a tool call of the category, or returns a *rateLimitError
+// when one of the limits is reached. The returned function must be called
+// once the call is done.
+func (p *quotaPolicy) acquire(req *mcp.CallToolRequest, category string) (func(), error) {
+	if p == nil || (len(p.rates) == 0 && len(p.concurrency) == 0) {
+		return func() {}, nil
+	}
+
+	var session *mcp.ServerSession
+	if req != nil {
+		session = req.Session
+	}
+	principal := p.principal(session)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	p.sweep(now)
+	quotas := []*quota{}
+	for _, limit := range []string{limitGlobal, limitSession, category} {
+		q := p.quota(limit, principal, now, true)
+		if q == nil {
+			continue
+		}
+
+		if maxActive, ok := p.concurrency[limit]; ok && q.active >= maxActive {
+			return nil, &rateLimitError{
+				Reason:           fmt.Sprintf("the %s limit of %d concurrent operations is reached", limit, maxActive),
+				Limit:            limit,
+				RetryAfterMillis: concurrencyRetryAfter.Milliseconds(),
+			}
+		}
+		if q.bucket != nil && q.bucket.tokens < 1 {
+			return nil, &rateLimitError{
+				Reason:           fmt.Sprintf("the %s limit of %s calls is reached", limit, q.bucket.limit),
+				Limit:            limit,
+				RetryAfterMillis: max(1, q.bucket.retryAfter().Milliseconds()),
+			}
+		}
+		quotas = append(quotas, q)
+	}
+
+	for _, q := range quotas {
+		if q.bucket != nil {
+			q.bucket.tokens--
+		}
+		q.active++
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			now := time.Now()
+			for _, q := range quotas {
+				q.active--
+				if q.bucket == nil && q.idle(now) && p.quotas[q.key] == q {
+					// Concurrency limits have no state left once idle.
+					delete(p.quotas, q.key)
+				}
+			}
+		})
+	}, nil
+}
+
+type MongoDBQuotaUsage struct {
+	Limit          string   `json:"limit" jsonschema:"The limit, global for the calls of every principal, session for the calls of this principal, or a category of operations of this principal: read, write, aggregate or admin"`
+	Rate           string   `json:"rate,omitempty" jsonschema:"The allowed rate of calls, e.g. 20/s"`
+	AvailableCalls *float64 `json:"available_calls,omitempty" jsonschema:"The number of calls allowed right away by the rate"`
+	RetryAfter     int64    `json:"retry_after_ms,omitempty" jsonschema:"The time until the next call is allowed by the rate in milliseconds, when none is"`
+	Active         int      `json:"active" jsonschema:"The number of calls in progress"`
+	MaxConcurrent  int      `json:"max_concurrent,omitempty" jsonschema:"The maximum number of calls in progress"`
+}
+
+// usage returns the usage of the configured limits by the principal of the
+// session.
+func (p *quotaPolicy) usage(session *mcp.ServerSession) (string, []MongoDBQuotaUsage) {
+	usages := []MongoDBQuotaUsage{}
+	if p == nil {
+		return "", usages
+	}
+	principal := p.principal(session)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	for _, limit := range quotaLimits {
+		q := p.quota(limit, principal, now, false)
+		if q == nil {
+			continue
+		}
+
+		usage := MongoDBQuotaUsage{
+			Limit:         limit,
+			Active:        q.active,
+			MaxConcurrent: p.concurrency[limit],
+		}
+		if q.bucket != nil {
+			available := math.Floor(q.bucket.tokens*100) / 100
+			usage.Rate = q.bucket.limit.String()
+			usage.AvailableCalls = &available
+			usage.RetryAfter = q.bucket.retryAfter().Milliseconds()
+		}
+		usages = append(usages, usage)
+	}
+
+	return principal, usages
+}
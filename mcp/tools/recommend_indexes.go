@@ -0,0 +1,383 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type MongoDBRecommendIndexesToolInput struct {
+	DatabaseName    *string          `json:"database_name,omitempty" jsonschema:"Optional name of the database of the collection"`
+	CollectionName  string           `json:"collection_name" jsonschema:"Name of the collection to recommend indexes for"`
+	Filter          bson.M           `json:"filter,omitempty" jsonschema:"Optional filter of the query to recommend an index for"`
+	Sort            []MongoDBSortKey `json:"sort,omitempty" jsonschema:"Optional ordered sort of the query to recommend an index for"`
+	Projection      bson.M           `json:"projection,omitempty" jsonschema:"Optional projection of the query, used to report whether the index covers the query"`
+	Pipeline        []bson.M         `json:"pipeline,omitempty" jsonschema:"Optional aggregation pipeline to recommend an index for, its leading $match and $sort stages are used"`
+	UseQueryHistory *bool            `json:"use_query_history,omitempty" jsonschema:"Optional whether to recommend indexes for the recent queries recorded by the database profiler, defaults to false"`
+	HistoryLimit    *int64           `json:"history_limit,omitempty" jsonschema:"Optional number of recent profiled queries to analyze, defaults to 20"`
+	SampleSize      *int64           `json:"sample_size,omitempty" jsonschema:"Optional number of documents to sample to estimate selectivity, defaults to 1000 which is also the maximum"`
+	MaxTimeMillis   *int64           `json:"max_time_ms,omitempty" jsonschema:"Optional time limit of the operation in milliseconds, defaults to the server timeout of the operation and is capped by the server maximum"`
+}
+
+type MongoDBIndexRecommendation struct {
+	Source           string                    `json:"source" jsonschema:"Where the query comes from, either input or profiler"`
+	Filter           bson.M                    `json:"filter" jsonschema:"The filter of the query"`
+	Sort             []MongoDBSortKey          `json:"sort,omitempty" jsonschema:"The ordered sort of the query"`
+	Keys             []MongoDBIndexKey         `json:"keys" jsonschema:"The recommended index keys, following the equality, sort, range rule"`
+	ExistingIndex    string                    `json:"existing_index,omitempty" jsonschema:"The name of an existing index that already starts with the recommended keys, in which case no index needs to be created"`
+	RedundantIndexes []string                  `json:"redundant_indexes,omitempty" jsonschema:"The existing indexes that are prefixes of the recommended index and become redundant once it is created"`
+	Covered          bool                      `json:"covered" jsonschema:"Whether the recommended index covers the projection of the query"`
+	Selectivity      *float64                  `json:"selectivity,omitempty" jsonschema:"The ratio of sampled documents matching the filter, lower is more selective"`
+	FieldSelectivity []MongoDBFieldSelectivity `json:"field_selectivity,omitempty" jsonschema:"The ratio of sampled documents matching the condition on each filtered field"`
+}
+
+type MongoDBRecommendIndexesToolOutput struct {
+	Recommendations []MongoDBIndexRecommendation `json:"recommendations" jsonschema:"The index recommendations, one per analyzed query"`
+	SampleSize      int64                        `json:"sample_size" jsonschema:"The number of documents sampled to estimate selectivity"`
+}
+
+type NewMongoDBRecommendIndexesTool struct {
+	tool *Tool
+}
+
+func (t *Tool) NewMongoDBRecommendIndexesTool() *NewMongoDBRecommendIndexesTool {
+	return &NewMongoDBRecommendIndexesTool{
+		tool: t,
+	}
+}
+
+func (t *NewMongoDBRecommendIndexesTool) name() string {
+	return "[MongoDB] Recommend Indexes Tool"
+}
+
+func (t *NewMongoDBRecommendIndexesTool) description() string {
+	return "# Recommend indexes in MongoDB.\n\n" +
+		"This tool proposes indexes for a query, an aggregation pipeline or the recent queries recorded by the " +
+		"database profiler, following the equality, sort, range rule.\n\n" +
+		"Each recommendation is checked against the existing indexes, flags existing indexes made redundant by it " +
+		"and estimates the selectivity of the filter by sampling the collection.\n\n"
+}
+
+type recommendationQuery struct {
+	source     string
+	filter     bson.M
+	sort       bson.D
+	projection bson.M
+}
+
+// pipelineQuery extracts the filter and sort of the leading $match and $sort
+// stages of the pipeline, the only ones that can use an index.
+func pipelineQuery(pipeline []bson.M) recommendationQuery {
+	query := recommendationQuery{source: "input", filter: bson.M{}}
+	for _, stage := range pipeline {
+		if match, ok := indexDocumentValue(stage["$match"]); ok && len(query.sort) == 0 {
+			for key, value := range match {
+				query.filter[key] = value
+			}
+			continue
+		}
+		if sortStage, ok := stage["$sort"]; ok && len(query.sort) == 0 {
+			if document, ok := sortStage.(bson.D); ok {
+				query.sort = document
+			} else if document, ok := indexDocumentValue(sortStage); ok {
+				// Sort documents decoded from JSON have lost their order, this
+				// is only reliable for a single field.
+				for key, value := range document {
+					query.sort = append(query.sort, bson.E{Key: key, Value: value})
+				}
+			}
+			continue
+		}
+		break
+	}
+	return query
+}
+
+// profiledQueries returns the filters and sorts of the recent operations on
+// the collection recorded by the database profiler.
+func (t *NewMongoDBRecommendIndexesTool) profiledQueries(ctx context.Context, collection *mongo.Collection, limit int64) ([]recommendationQuery, error) {
+	profile := collection.Database().Collection("system.profile")
+	cursor, err := profile.Find(ctx, bson.M{
+		"ns": collection.Database().Name() + "." + collection.Name(),
+		"op": bson.M{"$in": bson.A{"query", "command", "update", "remove", "getmore"}},
+	}, options.Find().SetSort(bson.D{{Key: "ts", Value: -1}}).SetLimit(limit))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	queries := []recommendationQuery{}
+	seen := map[string]bool{}
+	for cursor.Next(ctx) {
+		command, ok := cursor.Current.Lookup("command").DocumentOK()
+		if !ok {
+			continue
+		}
+
+		query := recommendationQuery{source: "profiler", filter: bson.M{}}
+		if pipeline := command.Lookup("pipeline"); pipeline.Type == bson.TypeArray {
+			var stages []bson.M
+			if err := pipeline.Unmarshal(&stages); err != nil {
+				continue
+			}
+			query = pipelineQuery(stages)
+			query.source = "profiler"
+		} else {
+			for _, key := range []string{"filter", "q", "query"} {
+				if filter, ok := command.Lookup(key).DocumentOK(); ok {
+					if err := bson.Unmarshal(filter, &query.filter); err != nil {
+						continue
+					}
+					break
+				}
+			}
+			if sort, ok := command.Lookup("sort").DocumentOK(); ok {
+				if err := bson.Unmarshal(sort, &query.sort); err != nil {
+					continue
+				}
+			}
+			if projection, ok := command.Lookup("projection").DocumentOK(); ok {
+				if err := bson.Unmarshal(projection, &query.projection); err != nil {
+					continue
+				}
+			}
+		}
+
+		signature := querySignature(query)
+		if (len(query.filter) == 0 && len(query.sort) == 0) || seen[signature] {
+			continue
+		}
+		seen[signature] = true
+		queries = append(queries, query)
+	}
+
+	return queries, cursor.Err()
+}
+
+// querySignature identifies the shape of a query, ignoring the values it
+// filters on.
+func querySignature(query recommendationQuery) string {
+	keys := []string{}
+	for _, key := range suggestIndex(query.filter, query.sort) {
+		keys = append(keys, fmt.Sprintf("%s:%v", key.Field, key.Value))
+	}
+	return strings.Join(keys, ",")
+}
+
+// selectivity estimates the ratio of documents matching the filter and the
+// condition of each of its fields on a sample of the collection.
+func (t *NewMongoDBRecommendIndexesTool) selectivity(
+	ctx context.Context,
+	collection *mongo.Collection,
+	scope bson.M,
+	filter bson.M,
+	sampleSize int64,
+) (*float64, []MongoDBFieldSelectivity, error) {
+	if len(filter) == 0 {
+		return nil, nil, nil
+	}
+
+	equality, ranges := filterFields(filter)
+	fields := append(append([]string{}, equality...), ranges...)
+
+	facets := bson.M{
+		"total":  bson.A{bson.M{"$count": "n"}},
+		"filter": bson.A{bson.M{"$match": filter}, bson.M{"$count": "n"}},
+	}
+	for i, field := range fields {
+		if condition, ok := filter[field]; ok {
+			facets[fmt.Sprintf("field_%d", i)] = bson.A{bson.M{"$match": bson.M{field: condition}}, bson.M{"$count": "n"}}
+		}
+	}
+
+	cursor, err := collection.Aggregate(ctx, append(scopedSample(scope, sampleSize), bson.M{"$facet": facets}))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if !cursor.Next(ctx) {
+		return nil, nil, cursor.Err()
+	}
+
+	count := func(facet string) int64 {
+		value, err := cursor.Current.LookupErr(facet, "0", "n")
+		if err != nil {
+			return 0
+		}
+		return value.AsInt64()
+	}
+
+	total := count("total")
+	if total == 0 {
+		return nil, nil, nil
+	}
+
+	filterSelectivity := float64(count("filter")) / float64(total)
+	fieldSelectivity := []MongoDBFieldSelectivity{}
+	for i, field := range fields {
+		if _, ok := filter[field]; !ok {
+			continue
+		}
+		fieldSelectivity = append(fieldSelectivity, MongoDBFieldSelectivity{
+			Field:       field,
+			Selectivity: float64(count(fmt.Sprintf("field_%d", i))) / float64(total),
+		})
+	}
+
+	return &filterSelectivity, fieldSelectivity, nil
+}
+
+func (t *NewMongoDBRecommendIndexesTool) toolCall(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	input MongoDBRecommendIndexesToolInput,
+) (
+	*mcp.CallToolResult,
+	MongoDBRecommendIndexesToolOutput,
+	error,
+) {
+	defResponse := MongoDBRecommendIndexesToolOutput{
+		Recommendations: []MongoDBIndexRecommendation{},
+	}
+
+	done, err := t.tool.quotas.acquire(req, operationRead)
+	if err != nil {
+		return nil, defResponse, err
+	}
+	defer done()
+
+	ctx, cancel := t.tool.timeouts.withTimeout(ctx, operationRead, input.MaxTimeMillis)
+	defer cancel()
+
+	if err := t.tool.filters.check(input.Filter, "filter"); err != nil {
+		return nil, defResponse, err
+	}
+
+	DB, err := t.tool.Database(input.DatabaseName)
+	if err != nil {
+		return nil, defResponse, err
+	}
+
+	collection := DB.Collection(input.CollectionName)
+	namespace := collectionNamespace(collection)
+
+	if err := t.tool.redaction.checkQuery(namespace, input.Filter, input.Sort); err != nil {
+		return nil, defResponse, err
+	}
+	if err := t.tool.redaction.checkPipeline(namespace, input.Pipeline); err != nil {
+		return nil, defResponse, err
+	}
+
+	scope, err := t.tool.scope.resolve(req.Session, namespace)
+	if err != nil {
+		return nil, defResponse, err
+	}
+
+	var sampleSize int64 = 1000
+	if input.SampleSize != nil && *input.SampleSize > 0 {
+		sampleSize = min(*input.SampleSize, maxSchemaSampleSize)
+	}
+	var historyLimit int64 = 20
+	if input.HistoryLimit != nil && *input.HistoryLimit > 0 {
+		historyLimit = *input.HistoryLimit
+	}
+
+	queries := []recommendationQuery{}
+	if len(input.Filter) > 0 || len(input.Sort) > 0 {
+		sort, err := sortDocument(input.Sort)
+		if err != nil {
+			return nil, defResponse, err
+		}
+		queries = append(queries, recommendationQuery{
+			source:     "input",
+			filter:     input.Filter,
+			sort:       sort,
+			projection: input.Projection,
+		})
+	}
+	if len(input.Pipeline) > 0 {
+		queries = append(queries, pipelineQuery(input.Pipeline))
+	}
+	if input.UseQueryHistory != nil && *input.UseQueryHistory {
+		if scope != nil {
+			return nil, defResponse, &scopeError{
+				Reason: "the query history holds the queries of every scope, it cannot be used on a scoped collection",
+			}
+		}
+		profiled, err := t.profiledQueries(ctx, collection, historyLimit)
+		if err != nil {
+			return nil, defResponse, err
+		}
+		for _, query := range profiled {
+			// The selectivity of the profiled queries on hidden fields
+			// would reveal them.
+			if t.tool.redaction.checkQuery(namespace, query.filter, sortKeys(query.sort)) == nil {
+				queries = append(queries, query)
+			}
+		}
+	}
+	if len(queries) == 0 {
+		return nil, defResponse, fmt.Errorf("Provide a filter, a sort, a pipeline or set use_query_history to recommend indexes")
+	}
+
+	indexes, err := listIndexes(ctx, collection)
+	if err != nil {
+		return nil, defResponse, err
+	}
+
+	output := MongoDBRecommendIndexesToolOutput{
+		Recommendations: []MongoDBIndexRecommendation{},
+		SampleSize:      sampleSize,
+	}
+	for _, query := range queries {
+		keys := suggestIndex(query.filter, query.sort)
+		if len(keys) == 0 {
+			continue
+		}
+
+		recommendation := MongoDBIndexRecommendation{
+			Source:  query.source,
+			Filter:  query.filter,
+			Sort:    sortKeys(query.sort),
+			Keys:    keys,
+			Covered: isCoveredProjection(query.projection, keys),
+		}
+		for _, index := range indexes {
+			// Partial and sparse indexes cannot serve every query on their
+			// keys.
+			if index.PartialFilterExpression != nil || index.Sparse {
+				continue
+			}
+			if isIndexPrefix(keys, index.Keys) && recommendation.ExistingIndex == "" {
+				recommendation.ExistingIndex = index.Name
+			} else if isIndexPrefix(index.Keys, keys) && index.Name != "_id_" {
+				recommendation.RedundantIndexes = append(recommendation.RedundantIndexes, index.Name)
+			}
+		}
+		if recommendation.ExistingIndex != "" {
+			recommendation.RedundantIndexes = nil
+		}
+
+		recommendation.Selectivity, recommendation.FieldSelectivity, err = t.selectivity(ctx, collection, scope, query.filter, sampleSize)
+		if err != nil {
+			return nil, defResponse, err
+		}
+
+		output.Recommendations = append(output.Recommendations, recommendation)
+	}
+
+	return nil, output, nil
+}
+
+func (t *NewMongoDBRecommendIndexesTool) AttachTool(server *mcp.Server) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        t.name(),
+		Description: t.description(),
+	}, t.toolCall)
+}
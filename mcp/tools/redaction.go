@@ -0,0 +1,839 @@
+package tools
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+const (
+	redactDrop = "drop"
+	redactMask = "mask"
+	redactHash = "hash"
+	redactType = "type"
+)
+
+// redactionValuePatterns are the named value patterns of the redaction
+// rules, other values are used as regular expressions.
+var redactionValuePatterns = map[string]string{
+	"email":       `[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`,
+	"credit_card": `\b(?:\d[ -]?){12,18}\d\b`,
+}
+
+// redactionRule hides the fields of the documents of the matching
+// namespaces, selected by path or by value.
+type redactionRule struct {
+	// Namespaces are "database.collection" globs, all namespaces when empty.
+	Namespaces []string `json:"namespaces"`
+	// Fields are dotted field paths where "*" matches one field and "**"
+	// any number of fields.
+	Fields []string `json:"fields"`
+	// Values are regular expressions, or the names email and credit_card,
+	// matched against the string values of any field.
+	Values []string `json:"values"`
+	// Mode is one of drop, mask, hash or type.
+	Mode string `json:"mode"`
+
+	values []*regexp.Regexp
+}
+
+// redactionPolicy redacts the documents returned by the tools, so that
+// sensitive values never reach the model.
+type redactionPolicy struct {
+	Rules []*redactionRule `json:"rules"`
+}
+
+// newRedactionPolicy loads the redaction rules of the JSON file at path, an
+// empty path disables redaction.
+func newRedactionPolicy(path string) (*redactionPolicy, error) {
+	policy := &redactionPolicy{}
+	if path == "" {
+		return policy, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, policy); err != nil {
+		return nil, err
+	}
+
+	for i, rule := range policy.Rules {
+		switch rule.Mode {
+		case redactDrop, redactMask, redactHash, redactType:
+		default:
+			return nil, fmt.Errorf("rule %d: invalid mode %q, use drop, mask, hash or type", i, rule.Mode)
+		}
+		for _, value := range rule.Values {
+			pattern, ok := redactionValuePatterns[value]
+			if !ok {
+				pattern = value
+			}
+			compiled, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("rule %d: invalid value pattern %q: %s", i, value, err.Error())
+			}
+			rule.values = append(rule.values, compiled)
+		}
+	}
+
+	return policy, nil
+}
+
+// collectionNamespace returns the "database.collection" namespace of the
+// collection.
+func collectionNamespace(collection *mongo.Collection) string {
+	return collection.Database().Name() + "." + collection.Name()
+}
+
+// matchFieldPattern reports whether the dotted field path matches the
+// pattern, "*" matching one field and "**" any number of fields.
+func matchFieldPattern(pattern, field []string) bool {
+	if len(pattern) == 0 {
+		return len(field) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(field); i++ {
+			if matchFieldPattern(pattern[1:], field[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(field) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(pattern[0], field[0]); !ok {
+		return false
+	}
+	return matchFieldPattern(pattern[1:], field[1:])
+}
+
+// rules returns the rules of the namespace.
+func (p *redactionPolicy) rules(namespace string) []*redactionRule {
+	if p == nil {
+		return nil
+	}
+
+	rules := []*redactionRule{}
+	for _, rule := range p.Rules {
+		if len(rule.Namespaces) == 0 {
+			rules = append(rules, rule)
+			continue
+		}
+		for _, pattern := range rule.Namespaces {
+			if ok, _ := path.Match(pattern, namespace); ok {
+				rules = append(rules, rule)
+				break
+			}
+		}
+	}
+	return rules
+}
+
+// fieldRule returns the first rule of the rules selecting the field path.
+func fieldRule(rules []*redactionRule, field string) *redactionRule {
+	segments := strings.Split(field, ".")
+	for _, rule := range rules {
+		for _, pattern := range rule.Fields {
+			if matchFieldPattern(strings.Split(pattern, "."), segments) {
+				return rule
+			}
+		}
+	}
+	return nil
+}
+
+// outerFieldRule returns the rule of the outermost of the field and its
+// parents selected by the rules, with the path it selects.
+func outerFieldRule(rules []*redactionRule, field string) (*redactionRule, string) {
+	segments := strings.Split(field, ".")
+	for i := range segments {
+		parent := strings.Join(segments[:i+1], ".")
+		if rule := fieldRule(rules, parent); rule != nil {
+			return rule, parent
+		}
+	}
+	return nil, ""
+}
+
+// hidden reports whether the field, or one of its parents, is fully hidden
+// from the results, so that filtering or sorting on it would reveal it.
+func (p *redactionPolicy) hidden(namespace, field string) bool {
+	return hiddenField(p.rules(namespace), field)
+}
+
+func hiddenField(rules []*redactionRule, field string) bool {
+	rule, _ := outerFieldRule(rules, field)
+	return rule != nil && (rule.Mode == redactDrop || rule.Mode == redactType)
+}
+
+// matchFieldPrefix reports whether the pattern matches the dotted field path
+// or one of its nested fields.
+func matchFieldPrefix(pattern, field []string) bool {
+	if len(field) == 0 {
+		return true
+	}
+	if len(pattern) == 0 {
+		return false
+	}
+	if pattern[0] == "**" {
+		return true
+	}
+	if ok, _ := path.Match(pattern[0], field[0]); !ok {
+		return false
+	}
+	return matchFieldPrefix(pattern[1:], field[1:])
+}
+
+// revealingField reports whether the value of the field may hold redacted
+// data: the field, one of its parents or one of its nested fields is
+// selected by the rules, or the rules match values. The root is the empty
+// field.
+func revealingField(rules []*redactionRule, field string) bool {
+	if len(rules) == 0 {
+		return false
+	}
+	if field == "" {
+		return true
+	}
+	segments := strings.Split(field, ".")
+	for _, rule := range rules {
+		if len(rule.values) > 0 {
+			return true
+		}
+		for _, pattern := range rule.Fields {
+			if matchFieldPrefix(strings.Split(pattern, "."), segments) {
+				return true
+			}
+		}
+	}
+	rule, _ := outerFieldRule(rules, field)
+	return rule != nil
+}
+
+// maskString keeps the last 4 characters of the value, whole runes so that
+// the result stays valid UTF-8.
+func maskString(value string) string {
+	runes := []rune(value)
+	if len(runes) <= 4 {
+		return "****"
+	}
+	return "****" + string(runes[len(runes)-4:])
+}
+
+func hashValue(value any) string {
+	var data []byte
+	if str, ok := value.(string); ok {
+		data = []byte(str)
+	} else if _, raw, err := bson.MarshalValue(value); err == nil {
+		data = raw
+	} else {
+		data = []byte(fmt.Sprint(value))
+	}
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:8])
+}
+
+func typeName(value any) string {
+	if value == nil {
+		return "null"
+	}
+	valueType, _, err := bson.MarshalValue(value)
+	if err != nil {
+		return "unknown"
+	}
+	return bsonTypeAlias(valueType)
+}
+
+// apply redacts the value with the mode of the rule, reporting false when
+// the field is dropped.
+func (r *redactionRule) apply(value any) (any, bool) {
+	switch r.Mode {
+	case redactDrop:
+		return nil, false
+	case redactMask:
+		if str, ok := value.(string); ok {
+			return maskString(str), true
+		}
+		return "****", true
+	case redactHash:
+		return hashValue(value), true
+	}
+	return "<redacted " + typeName(value) + ">", true
+}
+
+// applyValues redacts the parts of the string matching the value patterns
+// of the rules, reporting false when the field is dropped.
+func applyValues(rules []*redactionRule, value string) (any, bool) {
+	for _, rule := range rules {
+		for _, pattern := range rule.values {
+			if !pattern.MatchString(value) {
+				continue
+			}
+			switch rule.Mode {
+			case redactDrop:
+				return nil, false
+			case redactType:
+				return "<redacted string>", true
+			}
+			value = pattern.ReplaceAllStringFunc(value, func(match string) string {
+				redacted, _ := rule.apply(match)
+				return redacted.(string)
+			})
+		}
+	}
+	return value, true
+}
+
+// withoutIndexes removes the array indexes of a dotted field path, such as
+// the paths of the updated fields of a change event.
+func withoutIndexes(field string) string {
+	segments := []string{}
+	for _, segment := range strings.Split(field, ".") {
+		if _, err := strconv.Atoi(segment); err != nil {
+			segments = append(segments, segment)
+		}
+	}
+	return strings.Join(segments, ".")
+}
+
+// redactValue redacts the value of the field path, reporting false when the
+// field is dropped. Array elements share the path of their array, as in
+// queries.
+func redactValue(rules []*redactionRule, field string, value any) (any, bool) {
+	if field != "" {
+		if rule := fieldRule(rules, field); rule != nil {
+			return rule.apply(value)
+		}
+	}
+
+	child := func(key string) string {
+		if field == "" {
+			return key
+		}
+		return field + "." + key
+	}
+
+	switch typed := value.(type) {
+	case bson.M:
+		for key, nested := range typed {
+			if redacted, keep := redactValue(rules, child(key), nested); keep {
+				typed[key] = redacted
+			} else {
+				delete(typed, key)
+			}
+		}
+		return typed, true
+	case map[string]any:
+		return redactValue(rules, field, bson.M(typed))
+	case bson.D:
+		redacted := make(bson.D, 0, len(typed))
+		for _, element := range typed {
+			if value, keep := redactValue(rules, child(element.Key), element.Value); keep {
+				redacted = append(redacted, bson.E{Key: element.Key, Value: value})
+			}
+		}
+		return redacted, true
+	case bson.A:
+		redacted := make(bson.A, 0, len(typed))
+		for _, element := range typed {
+			if value, keep := redactValue(rules, field, element); keep {
+				redacted = append(redacted, value)
+			}
+		}
+		return redacted, true
+	case []any:
+		return redactValue(rules, field, bson.A(typed))
+	case string:
+		return applyValues(rules, typed)
+	}
+
+	return value, true
+}
+
+// redact redacts the document of the namespace in place.
+func (p *redactionPolicy) redact(namespace string, document bson.M) bson.M {
+	rules := p.rules(namespace)
+	if len(rules) == 0 || document == nil {
+		return document
+	}
+	redactValue(rules, "", document)
+	return document
+}
+
+// redactAll redacts the documents of the namespace in place.
+func (p *redactionPolicy) redactAll(namespace string, documents []bson.M) []bson.M {
+	for _, document := range documents {
+		p.redact(namespace, document)
+	}
+	return documents
+}
+
+// databaseRules returns the rules of the namespaces of the database.
+func (p *redactionPolicy) databaseRules(database string) []*redactionRule {
+	if p == nil {
+		return nil
+	}
+
+	rules := []*redactionRule{}
+	for _, rule := range p.Rules {
+		if len(rule.Namespaces) == 0 {
+			rules = append(rules, rule)
+			continue
+		}
+		for _, pattern := range rule.Namespaces {
+			databasePattern, _, _ := strings.Cut(pattern, ".")
+			if ok, _ := path.Match(databasePattern, database); ok {
+				rules = append(rules, rule)
+				break
+			}
+		}
+	}
+	return rules
+}
+
+// eventDocumentField returns the field of the watched documents read by the
+// field of a change event, the empty field for a whole document, reporting
+// false for the fields of the event itself.
+func eventDocumentField(field string) (string, bool) {
+	switch field {
+	case "", "fullDocument", "fullDocumentBeforeChange", "documentKey", "updateDescription", "updateDescription.updatedFields":
+		return "", true
+	}
+	for _, prefix := range []string{"fullDocument.", "fullDocumentBeforeChange.", "documentKey.", "updateDescription.updatedFields."} {
+		if strings.HasPrefix(field, prefix) {
+			return withoutIndexes(strings.TrimPrefix(field, prefix)), true
+		}
+	}
+	return "", false
+}
+
+// redactEvent redacts the documents of a change event, whose namespace is
+// given by its ns field.
+func (p *redactionPolicy) redactEvent(event bson.M) bson.M {
+	if p == nil || len(p.Rules) == 0 {
+		return event
+	}
+
+	ns, _ := pipelineDocument(event["ns"])
+	database, _ := ns["db"].(string)
+	collection, _ := ns["coll"].(string)
+	rules := p.rules(database + "." + collection)
+	if len(rules) == 0 {
+		return event
+	}
+
+	for _, key := range []string{"fullDocument", "fullDocumentBeforeChange", "documentKey"} {
+		if document, ok := event[key]; ok && document != nil {
+			event[key], _ = redactValue(rules, "", document)
+		}
+	}
+
+	// The updated fields are keyed by their dotted path.
+	description, ok := pipelineDocument(event["updateDescription"])
+	if !ok {
+		return event
+	}
+	updated, ok := pipelineDocument(description["updatedFields"])
+	if !ok {
+		return event
+	}
+	redacted := bson.M{}
+	for field, value := range updated {
+		if rule, _ := outerFieldRule(rules, withoutIndexes(field)); rule != nil {
+			value, keep := rule.apply(value)
+			if keep {
+				redacted[field] = value
+			}
+			continue
+		}
+		if value, keep := redactValue(rules, withoutIndexes(field), value); keep {
+			redacted[field] = value
+		}
+	}
+	description["updatedFields"] = redacted
+	event["updateDescription"] = description
+
+	return event
+}
+
+// redactSchema returns a copy of the inferred schema of the namespace
+// without the fields hidden by the rules and the redacted examples.
+func (p *redactionPolicy) redactSchema(namespace string, schema *MongoDBCollectionSchema) *MongoDBCollectionSchema {
+	rules := p.rules(namespace)
+	if len(rules) == 0 || schema == nil {
+		return schema
+	}
+
+	redacted := *schema
+	redacted.Fields = []MongoDBSchemaField{}
+	redacted.ObjectIDFields = []string{}
+	redacted.DateFields = []string{}
+	for _, field := range schema.Fields {
+		rule, ruleField := outerFieldRule(rules, field.Path)
+		switch {
+		case rule == nil:
+			examples := []string{}
+			for _, example := range field.Examples {
+				if value, keep := applyValues(rules, example); keep {
+					examples = append(examples, value.(string))
+				}
+			}
+			field.Examples = examples
+		case rule.Mode == redactDrop || ruleField != field.Path:
+			// The nested fields of a redacted field are not returned.
+			continue
+		default:
+			field.Examples = nil
+		}
+		if len(field.Examples) == 0 {
+			field.Examples = nil
+		}
+
+		redacted.Fields = append(redacted.Fields, field)
+		if field.IsObjectID {
+			redacted.ObjectIDFields = append(redacted.ObjectIDFields, field.Path)
+		}
+		if field.IsDate {
+			redacted.DateFields = append(redacted.DateFields, field.Path)
+		}
+	}
+
+	return &redacted
+}
+
+// hiddenFieldError returns the error refusing a query on a hidden field, or
+// a copy of a redacted one.
+func hiddenFieldError(field, path string) error {
+	return &filterPolicyError{
+		Reason: fmt.Sprintf("the field %s is redacted by the redaction policy and cannot be queried or copied", field),
+		Path:   path,
+	}
+}
+
+// referencedField returns the field of a "$field" reference, the empty field
+// for the $$ROOT and $$CURRENT variables, reporting false for the other
+// strings.
+func referencedField(value string) (string, bool) {
+	for _, variable := range []string{"$$ROOT", "$$CURRENT"} {
+		if value == variable {
+			return "", true
+		}
+		if strings.HasPrefix(value, variable+".") {
+			return strings.TrimPrefix(value, variable+"."), true
+		}
+	}
+	if strings.HasPrefix(value, "$") && !strings.HasPrefix(value, "$$") {
+		return value[1:], true
+	}
+	return "", false
+}
+
+// checkReferences refuses the "$field", "$$ROOT" and "$$CURRENT" references
+// selected by hidden in an aggregation expression.
+func checkReferences(hidden func(field string) bool, value any, path string) error {
+	if str, ok := value.(string); ok {
+		if field, ok := referencedField(str); ok && hidden(field) {
+			if field == "" {
+				field = str
+			}
+			return hiddenFieldError(field, path)
+		}
+		return nil
+	}
+	if document, ok := pipelineDocument(value); ok {
+		for key, nested := range document {
+			if err := checkReferences(hidden, nested, path+"."+key); err != nil {
+				return err
+			}
+		}
+	}
+	if values, ok := pipelineStages(value); ok {
+		for i, nested := range values {
+			if err := checkReferences(hidden, nested, fmt.Sprintf("%s.%d", path, i)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkFilter refuses the filters on hidden fields, field is the path the
+// filter applies to, empty at the top level.
+func checkFilter(hidden func(field string) bool, value any, field, path string) error {
+	if values, ok := pipelineStages(value); ok {
+		for i, nested := range values {
+			if err := checkFilter(hidden, nested, field, fmt.Sprintf("%s.%d", path, i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	document, ok := pipelineDocument(value)
+	if !ok {
+		return nil
+	}
+	for key, nested := range document {
+		keyPath := path + "." + key
+		switch {
+		case key == "$expr":
+			if err := checkReferences(hidden, nested, keyPath); err != nil {
+				return err
+			}
+		case strings.HasPrefix(key, "$"):
+			if err := checkFilter(hidden, nested, field, keyPath); err != nil {
+				return err
+			}
+		default:
+			nestedField := key
+			if field != "" {
+				nestedField = field + "." + key
+			}
+			if hidden(nestedField) {
+				return hiddenFieldError(nestedField, keyPath)
+			}
+			if err := checkFilter(hidden, nested, nestedField, keyPath); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkQuery refuses the queries filtering or sorting on the fields hidden
+// in the namespace.
+func (p *redactionPolicy) checkQuery(namespace string, filter bson.M, sort []MongoDBSortKey) error {
+	rules := p.rules(namespace)
+	if len(rules) == 0 {
+		return nil
+	}
+
+	hidden := func(field string) bool { return hiddenField(rules, field) }
+	if err := checkFilter(hidden, filter, "", "filter"); err != nil {
+		return err
+	}
+	for i, key := range sort {
+		if hidden(key.Field) {
+			return hiddenFieldError(key.Field, fmt.Sprintf("sort.%d", i))
+		}
+	}
+	return nil
+}
+
+// checkProjection refuses the projections of the namespace computing fields
+// from the fields that may hold redacted data, such as {"leak": "$ssn"},
+// whose results the rules would no longer select. Inclusions and exclusions
+// keep the paths of the fields and are redacted as such.
+func (p *redactionPolicy) checkProjection(namespace string, projection bson.M) error {
+	rules := p.rules(namespace)
+	if len(rules) == 0 {
+		return nil
+	}
+
+	hidden := func(field string) bool { return hiddenField(rules, field) }
+	revealing := func(field string) bool { return revealingField(rules, field) }
+	for field, value := range projection {
+		fieldPath := "projection." + field
+		if document, ok := pipelineDocument(value); ok {
+			if elemMatch, ok := document["$elemMatch"]; ok {
+				if err := checkFilter(hidden, elemMatch, field, fieldPath+".$elemMatch"); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if err := checkReferences(revealing, value, fieldPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkPipeline refuses the pipelines matching or sorting on the fields
+// hidden in the namespace, or copying fields that may hold redacted data to
+// paths the rules do not select.
+func (p *redactionPolicy) checkPipeline(namespace string, pipeline []bson.M) error {
+	if p == nil || len(p.Rules) == 0 {
+		return nil
+	}
+
+	stages := make([]any, 0, len(pipeline))
+	for _, stage := range pipeline {
+		stages = append(stages, stage)
+	}
+	return p.checkStages(namespace, stages, "pipeline")
+}
+
+// checkStages checks the stages of a pipeline reading the documents of the
+// namespace, and refuses the joins of the namespaces with rules, whose
+// joined documents would not be redacted.
+func (p *redactionPolicy) checkStages(namespace string, stages []any, stagesPath string) error {
+	database, _, _ := strings.Cut(namespace, ".")
+	rules := p.rules(namespace)
+	hidden := func(field string) bool { return hiddenField(rules, field) }
+	revealing := func(field string) bool { return revealingField(rules, field) }
+
+	for i, value := range stages {
+		stage, ok := pipelineDocument(value)
+		if !ok {
+			continue
+		}
+		stagePath := fmt.Sprintf("%s.%d", stagesPath, i)
+		for name, spec := range stage {
+			var err error
+			switch name {
+			case "$match":
+				err = checkFilter(hidden, spec, "", stagePath+".$match")
+			case "$sort":
+				document, _ := pipelineDocument(spec)
+				for field := range document {
+					if hidden(field) {
+						err = hiddenFieldError(field, stagePath+".$sort."+field)
+						break
+					}
+				}
+			case "$lookup", "$unionWith", "$graphLookup":
+				err = p.checkJoin(namespace, database, name, spec, stagePath+"."+name)
+			case "$facet":
+				document, _ := pipelineDocument(spec)
+				for facet, value := range document {
+					if facetStages, ok := pipelineStages(value); ok {
+						if err = p.checkStages(namespace, facetStages, stagePath+".$facet."+facet); err != nil {
+							break
+						}
+					}
+				}
+			default:
+				// The computed fields are not redacted, whether they copy a
+				// masked value, a parent of a redacted field or the whole
+				// document.
+				err = checkReferences(revealing, spec, stagePath+"."+name)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// joinedNamespace returns the namespace read by a $lookup, $unionWith or
+// $graphLookup stage, empty for a $lookup on the documents of its pipeline.
+func joinedNamespace(database, name string, spec any) string {
+	if coll, ok := spec.(string); ok && name == "$unionWith" {
+		return database + "." + coll
+	}
+	document, ok := pipelineDocument(spec)
+	if !ok {
+		return ""
+	}
+
+	fromKey := "from"
+	if name == "$unionWith" {
+		fromKey = "coll"
+	}
+	if from, ok := pipelineDocument(document[fromKey]); ok {
+		if db, ok := from["db"].(string); ok && db != "" {
+			database = db
+		}
+		coll, _ := from["coll"].(string)
+		return database + "." + coll
+	}
+	if coll, ok := document[fromKey].(string); ok && coll != "" {
+		return database + "." + coll
+	}
+	return ""
+}
+
+// checkJoin refuses the joins of a namespace with rules and checks the
+// fields of the namespace used by the join and its sub-pipeline.
+func (p *redactionPolicy) checkJoin(namespace, database, name string, spec any, stagePath string) error {
+	joined := joinedNamespace(database, name, spec)
+	if joined != "" && len(p.rules(joined)) > 0 {
+		return &filterPolicyError{
+			Reason: fmt.Sprintf("the %s stage joins the collection %s, whose fields are redacted", name, joined),
+			Path:   stagePath,
+		}
+	}
+
+	rules := p.rules(namespace)
+	hidden := func(field string) bool { return hiddenField(rules, field) }
+	revealing := func(field string) bool { return revealingField(rules, field) }
+	document, _ := pipelineDocument(spec)
+	for key, value := range document {
+		var err error
+		switch key {
+		case "pipeline":
+			if stages, ok := pipelineStages(value); ok {
+				// The sub-pipeline reads the joined documents, or its own
+				// documents such as $documents, checked as the documents of
+				// the namespace.
+				subNamespace := joined
+				if subNamespace == "" {
+					subNamespace = namespace
+				}
+				err = p.checkStages(subNamespace, stages, stagePath+".pipeline")
+			}
+		case "localField":
+			if field, ok := value.(string); ok && hidden(field) {
+				err = hiddenFieldError(field, stagePath+".localField")
+			}
+		case "let", "startWith":
+			err = checkReferences(revealing, value, stagePath+"."+key)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkEventPipeline refuses the change stream pipelines on the collection,
+// or on every collection of the database when it is empty, matching on the
+// hidden fields of the documents, or copying fields that may hold redacted
+// data out of the documents redacted in the events.
+func (p *redactionPolicy) checkEventPipeline(database, collection string, pipeline []bson.M) error {
+	rules := p.databaseRules(database)
+	if collection != "" {
+		rules = p.rules(database + "." + collection)
+	}
+	if len(rules) == 0 {
+		return nil
+	}
+
+	hidden := func(field string) bool {
+		documentField, ok := eventDocumentField(field)
+		return ok && documentField != "" && hiddenField(rules, documentField)
+	}
+	revealing := func(field string) bool {
+		documentField, ok := eventDocumentField(field)
+		return ok && revealingField(rules, documentField)
+	}
+	for i, stage := range pipeline {
+		stagePath := fmt.Sprintf("pipeline.%d", i)
+		for name, spec := range stage {
+			var err error
+			if name == "$match" {
+				err = checkFilter(hidden, spec, "", stagePath+".$match")
+			} else {
+				err = checkReferences(revealing, spec, stagePath+"."+name)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
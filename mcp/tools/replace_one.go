@@ -0,0 +1,140 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type MongoDBReplaceOneToolInput struct {
+	DatabaseName        *string                `json:"database_name,omitempty" jsonschema:"Optional name of the database to find the document in"`
+	CollectionName      string                 `json:"collection_name" jsonschema:"Name of the collection to find the document in"`
+	Filter              bson.M                 `json:"filter" jsonschema:"The filter to find the document with"`
+	Replacement         bson.M                 `json:"replacement" jsonschema:"The document to replace the existing document with"`
+	Upsert              *bool                  `json:"upsert,omitempty" jsonschema:"Optional whether to insert the document if it doesn't exist, defaults to false"`
+	Hint                *string                `json:"hint,omitempty" jsonschema:"Optional name of the index to use"`
+	Collation           *MongoDBCollationInput `json:"collation,omitempty" jsonschema:"Optional collation used to match the filter"`
+	Let                 bson.M                 `json:"let,omitempty" jsonschema:"Optional variables accessible as $$<name> in the filter"`
+	AllowCollectionScan *bool                  `json:"allow_collection_scan,omitempty" jsonschema:"Optional confirmation to run the operation even if the query cost guard detects a collection scan, only honoured when the server asks for confirmation"`
+	TransactionID       *string                `json:"transaction_id,omitempty" jsonschema:"Optional id of a transaction returned by the Begin Transaction tool to run the operation in"`
+	MaxTimeMillis       *int64                 `json:"max_time_ms,omitempty" jsonschema:"Optional time limit of the operation in milliseconds, defaults to the server timeout of the operation and is capped by the server maximum"`
+}
+
+type MongoDBReplaceOneToolOutput struct {
+	Result *mongo.UpdateResult `json:"result" jsonschema:"The result of the replace operation"`
+}
+
+type NewMongoDBReplaceOneTool struct {
+	tool *Tool
+}
+
+func (t *Tool) NewMongoDBReplaceOneTool() *NewMongoDBReplaceOneTool {
+	return &NewMongoDBReplaceOneTool{
+		tool: t,
+	}
+}
+
+func (t *NewMongoDBReplaceOneTool) name() string {
+	return "[MongoDB] Replace One Tool"
+}
+
+func (t *NewMongoDBReplaceOneTool) description() string {
+	return "# Replace one document in MongoDB.\n\n" +
+		"This tool can be used to replace the whole content of one document in a MongoDB collection, keeping its _id.\n\n"
+}
+
+func (t *NewMongoDBReplaceOneTool) toolCall(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	input MongoDBReplaceOneToolInput,
+) (
+	*mcp.CallToolResult,
+	MongoDBReplaceOneToolOutput,
+	error,
+) {
+	defResponse := MongoDBReplaceOneToolOutput{
+		Result: nil,
+	}
+
+	done, err := t.tool.quotas.acquire(req, operationWrite)
+	if err != nil {
+		return nil, defResponse, err
+	}
+	defer done()
+
+	ctx, cancel := t.tool.timeouts.withTimeout(ctx, operationWrite, input.MaxTimeMillis)
+	defer cancel()
+
+	if len(input.Replacement) == 0 {
+		return nil, defResponse, fmt.Errorf("The replacement document must not be empty")
+	}
+
+	if err := t.tool.filters.check(input.Filter, "filter"); err != nil {
+		return nil, defResponse, err
+	}
+
+	DB, err := t.tool.Database(input.DatabaseName)
+	if err != nil {
+		return nil, defResponse, err
+	}
+
+	collection := DB.Collection(input.CollectionName)
+
+	if err := t.tool.redaction.checkQuery(collectionNamespace(collection), input.Filter, nil); err != nil {
+		return nil, defResponse, err
+	}
+
+	scope, err := t.tool.scope.resolve(req.Session, collectionNamespace(collection))
+	if err != nil {
+		return nil, defResponse, err
+	}
+	filter := scopedFilter(scope, input.Filter)
+	if err := scopeDocument(scope, input.Replacement, "replacement"); err != nil {
+		return nil, defResponse, err
+	}
+
+	query := explainQuery{operation: "find", filter: filter, limit: 1}
+	if err := t.tool.guard.check(ctx, collection, query, input.AllowCollectionScan); err != nil {
+		return nil, defResponse, err
+	}
+
+	ctx, release, err := t.tool.transactions.join(ctx, req.Session, input.TransactionID)
+	if err != nil {
+		return nil, defResponse, err
+	}
+	defer release()
+
+	opts := options.Replace()
+	if input.Upsert != nil && *input.Upsert {
+		opts.SetUpsert(*input.Upsert)
+	}
+	if input.Hint != nil && *input.Hint != "" {
+		opts.SetHint(*input.Hint)
+	}
+	if input.Collation != nil {
+		opts.SetCollation(input.Collation.options())
+	}
+	if len(input.Let) > 0 {
+		opts.SetLet(input.Let)
+	}
+
+	res, err := collection.ReplaceOne(ctx, filter, input.Replacement, opts)
+	if err != nil {
+		return nil, defResponse, err
+	}
+
+	return nil, MongoDBReplaceOneToolOutput{
+		Result: res,
+	}, nil
+}
+
+func (t *NewMongoDBReplaceOneTool) AttachTool(server *mcp.Server) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        t.name(),
+		Description: t.description(),
+	}, t.toolCall)
+}
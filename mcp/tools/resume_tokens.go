@@ -0,0 +1,99 @@
+package tools
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// resumeTokenStore keeps the last resume token of each subscribed resource,
+// so that a subscription resumes where the previous one stopped. Tokens are
+// written to the file at path when it is set, to survive server restarts.
+type resumeTokenStore struct {
+	mu     sync.Mutex
+	path   string
+	tokens map[string]bson.Raw
+}
+
+func newResumeTokenStore(path string) (*resumeTokenStore, error) {
+	store := &resumeTokenStore{
+		path:   path,
+		tokens: map[string]bson.Raw{},
+	}
+	if path == "" {
+		return store, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var tokens map[string]json.RawMessage
+	if err := json.Unmarshal(data, &tokens); err != nil {
+		return nil, err
+	}
+	for uri, token := range tokens {
+		var raw bson.Raw
+		if err := bson.UnmarshalExtJSON(token, true, &raw); err != nil {
+			return nil, err
+		}
+		store.tokens[uri] = raw
+	}
+
+	return store, nil
+}
+
+func (s *resumeTokenStore) get(uri string) (bson.Raw, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token, ok := s.tokens[uri]
+	return token, ok
+}
+
+func (s *resumeTokenStore) set(uri string, token bson.Raw) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if token == nil {
+		delete(s.tokens, uri)
+	} else {
+		// The token points into the current batch of the change stream.
+		s.tokens[uri] = append(bson.Raw(nil), token...)
+	}
+
+	return s.save()
+}
+
+func (s *resumeTokenStore) save() error {
+	if s.path == "" {
+		return nil
+	}
+
+	tokens := map[string]json.RawMessage{}
+	for uri, token := range s.tokens {
+		data, err := bson.MarshalExtJSON(token, true, false)
+		if err != nil {
+			return err
+		}
+		tokens[uri] = data
+	}
+
+	data, err := json.Marshal(tokens)
+	if err != nil {
+		return err
+	}
+
+	// Write and rename so that a crash never leaves a truncated file.
+	temporary := s.path + ".tmp"
+	if err := os.WriteFile(temporary, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(temporary, s.path)
+}
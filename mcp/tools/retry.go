@@ -0,0 +1,126 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// retryMetaKey is the key of the retry count in the metadata of the tool
+// results.
+const retryMetaKey = "retries"
+
+// retryableReadCodes are the server error codes after which a read can be
+// retried, the same as the driver retries once on its own.
+var retryableReadCodes = []int{
+	6,     // HostUnreachable
+	7,     // HostNotFound
+	89,    // NetworkTimeout
+	91,    // ShutdownInProgress
+	134,   // ReadConcernMajorityNotAvailableYet
+	189,   // PrimarySteppedDown
+	262,   // ExceededTimeLimit
+	9001,  // SocketException
+	10107, // NotWritablePrimary
+	11600, // InterruptedAtShutdown
+	11602, // InterruptedDueToReplStateChange
+	13435, // NotPrimaryNoSecondaryOk
+	13436, // NotPrimaryOrSecondary
+}
+
+// retryPolicy retries the read tools on transient failures, waiting a
+// jittered exponential backoff between the attempts.
+type retryPolicy struct {
+	maxAttempts    int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+}
+
+// retriedError is the error of an operation that failed after retries.
+type retriedError struct {
+	err     error
+	retries int
+}
+
+func (e *retriedError) Error() string {
+	return fmt.Sprintf("%s (after %d retries)", e.err.Error(), e.retries)
+}
+
+func (e *retriedError) Unwrap() error {
+	return e.err
+}
+
+// retryableRead reports whether a read failed with a transient error.
+func retryableRead(err error) bool {
+	var labeled mongo.LabeledError
+	if errors.As(err, &labeled) && labeled.HasErrorLabel("NetworkError") {
+		return true
+	}
+
+	var commandErr mongo.CommandError
+	if errors.As(err, &commandErr) {
+		return slices.Contains(retryableReadCodes, int(commandErr.Code))
+	}
+
+	return mongo.IsNetworkError(err)
+}
+
+// backoff returns the wait before the given retry, a random duration between
+// half and all of the exponential backoff.
+func (p *retryPolicy) backoff(retry int) time.Duration {
+	backoff := p.initialBackoff
+	for i := 1; i < retry && backoff < p.maxBackoff; i++ {
+		backoff *= 2
+	}
+	backoff = min(backoff, p.maxBackoff)
+	if backoff <= 0 {
+		return 0
+	}
+
+	return backoff/2 + rand.N(backoff/2+1)
+}
+
+// do runs the read until it succeeds, fails with an error that is not
+// transient or runs out of attempts, and returns the number of retries.
+// Reads in a transaction are not retried, as the whole transaction has to be
+// retried instead.
+func (p *retryPolicy) do(ctx context.Context, read func(ctx context.Context) error) (int, error) {
+	retries := 0
+	for {
+		err := read(ctx)
+		if err == nil {
+			return retries, nil
+		}
+
+		if mongo.SessionFromContext(ctx) != nil || ctx.Err() != nil ||
+			retries+1 >= p.maxAttempts || !retryableRead(err) {
+			if retries > 0 {
+				return retries, &retriedError{err: err, retries: retries}
+			}
+			return retries, err
+		}
+
+		retries++
+		timer := time.NewTimer(p.backoff(retries))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return retries, &retriedError{err: err, retries: retries}
+		case <-timer.C:
+		}
+	}
+}
+
+// retryResult returns the result of a read tool reporting its retry count in
+// the metadata.
+func retryResult(retries int) *mcp.CallToolResult {
+	return &mcp.CallToolResult{
+		Meta: mcp.Meta{retryMetaKey: retries},
+	}
+}
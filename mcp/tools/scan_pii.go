@@ -0,0 +1,148 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type MongoDBScanPIIToolInput struct {
+	DatabaseName   *string  `json:"database_name,omitempty" jsonschema:"Optional name of the database of the collection"`
+	CollectionName string   `json:"collection_name" jsonschema:"Name of the collection to scan"`
+	SampleSize     *int64   `json:"sample_size,omitempty" jsonschema:"Optional number of documents to sample, defaults to 100 and is capped at 1000"`
+	MinConfidence  *float64 `json:"min_confidence,omitempty" jsonschema:"Optional minimum confidence of the reported fields, from 0 to 1, defaults to 0.5"`
+	RedactionMode  *string  `json:"redaction_mode,omitempty" jsonschema:"Optional mode of the suggested redaction rule, one of drop, mask, hash or type, defaults to mask"`
+	MaxTimeMillis  *int64   `json:"max_time_ms,omitempty" jsonschema:"Optional time limit of the operation in milliseconds, defaults to the server timeout of the operation and is capped by the server maximum"`
+}
+
+type MongoDBScanPIIToolOutput struct {
+	SampleSize      int               `json:"sample_size" jsonschema:"The number of documents that were sampled"`
+	Fields          []MongoDBPIIField `json:"fields" jsonschema:"The fields likely holding personal data, by decreasing confidence"`
+	RedactionPolicy *redactionPolicy  `json:"redaction_policy" jsonschema:"A redaction policy hiding the reported fields, to merge into the file of REDACTION_POLICY_FILE"`
+}
+
+type NewMongoDBScanPIITool struct {
+	tool *Tool
+}
+
+func (t *Tool) NewMongoDBScanPIITool() *NewMongoDBScanPIITool {
+	return &NewMongoDBScanPIITool{
+		tool: t,
+	}
+}
+
+func (t *NewMongoDBScanPIITool) name() string {
+	return "[MongoDB] Scan PII Tool"
+}
+
+func (t *NewMongoDBScanPIITool) description() string {
+	return "# Detect the fields of a MongoDB collection holding personal data.\n\n" +
+		"This tool samples documents from a MongoDB collection and classifies the fields that likely contain " +
+		"personal data: emails, phone numbers, IBANs, credit card numbers passing the Luhn check, national IDs, " +
+		"IP addresses and names. Each field is reported with a confidence score and masked example values.\n\n" +
+		"It also returns a redaction policy hiding the reported fields, ready to be added to the redaction " +
+		"policy file of the server. The documents are redacted by the current policy before they are scanned, " +
+		"so the fields it already hides are not reported.\n\n"
+}
+
+func (t *NewMongoDBScanPIITool) toolCall(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	input MongoDBScanPIIToolInput,
+) (
+	*mcp.CallToolResult,
+	MongoDBScanPIIToolOutput,
+	error,
+) {
+	defResponse := MongoDBScanPIIToolOutput{
+		SampleSize:      0,
+		Fields:          []MongoDBPIIField{},
+		RedactionPolicy: nil,
+	}
+
+	done, err := t.tool.quotas.acquire(req, operationRead)
+	if err != nil {
+		return nil, defResponse, err
+	}
+	defer done()
+
+	ctx, cancel := t.tool.timeouts.withTimeout(ctx, operationRead, input.MaxTimeMillis)
+	defer cancel()
+
+	minConfidence := defaultPIIMinConfidence
+	if input.MinConfidence != nil {
+		if *input.MinConfidence < 0 || *input.MinConfidence > 1 {
+			return nil, defResponse, fmt.Errorf("Invalid min_confidence %v, it must be between 0 and 1", *input.MinConfidence)
+		}
+		minConfidence = *input.MinConfidence
+	}
+
+	mode := redactMask
+	if input.RedactionMode != nil {
+		switch *input.RedactionMode {
+		case redactDrop, redactMask, redactHash, redactType:
+			mode = *input.RedactionMode
+		default:
+			return nil, defResponse, fmt.Errorf("Invalid redaction_mode %q, use drop, mask, hash or type", *input.RedactionMode)
+		}
+	}
+
+	DB, err := t.tool.Database(input.DatabaseName)
+	if err != nil {
+		return nil, defResponse, err
+	}
+
+	collection := DB.Collection(input.CollectionName)
+	namespace := collectionNamespace(collection)
+
+	scope, err := t.tool.scope.resolve(req.Session, namespace)
+	if err != nil {
+		return nil, defResponse, err
+	}
+
+	sampleSize := defaultSchemaSampleSize
+	if input.SampleSize != nil && *input.SampleSize > 0 {
+		sampleSize = min(*input.SampleSize, maxSchemaSampleSize)
+	}
+
+	var scan *piiScan
+	var sampled int
+	retries, err := t.tool.retry.do(ctx, func(ctx context.Context) error {
+		scan, sampled = newPIIScan(), 0
+
+		cursor, err := collection.Aggregate(ctx, scopedSample(scope, sampleSize))
+		if err != nil {
+			return err
+		}
+		defer cursor.Close(ctx)
+
+		for cursor.Next(ctx) {
+			var document bson.M
+			if err := cursor.Decode(&document); err != nil {
+				return err
+			}
+			scan.addDocument(t.tool.redaction.redact(namespace, document))
+			sampled++
+		}
+		return cursor.Err()
+	})
+	if err != nil {
+		return nil, defResponse, err
+	}
+
+	fields := scan.results(minConfidence)
+	return retryResult(retries), MongoDBScanPIIToolOutput{
+		SampleSize:      sampled,
+		Fields:          fields,
+		RedactionPolicy: piiRedactionPolicy(namespace, fields, mode),
+	}, nil
+}
+
+func (t *NewMongoDBScanPIITool) AttachTool(server *mcp.Server) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        t.name(),
+		Description: t.description(),
+	}, t.toolCall)
+}
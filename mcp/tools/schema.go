@@ -0,0 +1,462 @@
+package tools
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+const (
+	defaultSchemaSampleSize int64 = 100
+	maxSchemaSampleSize     int64 = 1000
+	maxSchemaExamples             = 3
+	maxSchemaExampleLength        = 80
+)
+
+var objectIDStringPattern = regexp.MustCompile(`^[0-9a-fA-F]{24}$`)
+
+type MongoDBSchemaTypeFrequency struct {
+	Type      string  `json:"type" jsonschema:"The BSON type alias, as used by the $type operator"`
+	Count     int     `json:"count" jsonschema:"The number of sampled values with this type"`
+	Frequency float64 `json:"frequency" jsonschema:"The ratio of sampled values with this type"`
+}
+
+type MongoDBSchemaField struct {
+	Path              string                       `json:"path" jsonschema:"The dot notation path of the field"`
+	Types             []MongoDBSchemaTypeFrequency `json:"types" jsonschema:"The types seen for the field with their frequencies"`
+	PresenceRatio     float64                      `json:"presence_ratio" jsonschema:"The ratio of parent documents that contain the field"`
+	ArrayElementTypes []MongoDBSchemaTypeFrequency `json:"array_element_types,omitempty" jsonschema:"The types seen for the array elements, when the field is an array"`
+	Examples          []string                     `json:"examples,omitempty" jsonschema:"Example values of the field in extended JSON"`
+	IsObjectID        bool                         `json:"is_object_id" jsonschema:"Whether the field holds ObjectIds or ObjectId-like hex strings"`
+	IsDate            bool                         `json:"is_date" jsonschema:"Whether the field holds dates or date-like strings"`
+}
+
+type MongoDBCollectionSchema struct {
+	SampleSize     int                  `json:"sample_size" jsonschema:"The number of documents that were sampled"`
+	Fields         []MongoDBSchemaField `json:"fields" jsonschema:"The inferred fields, ordered by path"`
+	ObjectIDFields []string             `json:"object_id_fields" jsonschema:"The paths of the fields detected as ObjectIds"`
+	DateFields     []string             `json:"date_fields" jsonschema:"The paths of the fields detected as dates"`
+	JSONSchema     map[string]any       `json:"json_schema" jsonschema:"The inferred schema as a JSON Schema document"`
+}
+
+type schemaNode struct {
+	count       int
+	objectCount int
+	types       map[string]int
+	arrayTypes  map[string]int
+	examples    []string
+	strings     int
+	oidStrings  int
+	dateStrings int
+	children    map[string]*schemaNode
+}
+
+type schemaCacheEntry struct {
+	schema  *MongoDBCollectionSchema
+	expires time.Time
+}
+
+// schemaCache keeps inferred schemas per namespace for a limited time, a ttl
+// of zero disables the cache.
+type schemaCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]schemaCacheEntry
+}
+
+func newSchemaCache(ttl time.Duration) *schemaCache {
+	return &schemaCache{
+		ttl:     ttl,
+		entries: map[string]schemaCacheEntry{},
+	}
+}
+
+func (c *schemaCache) get(namespace string) (*MongoDBCollectionSchema, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[namespace]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(entry.expires) {
+		delete(c.entries, namespace)
+		return nil, false
+	}
+	return entry.schema, true
+}
+
+func (c *schemaCache) set(namespace string, schema *MongoDBCollectionSchema) {
+	if c.ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[namespace] = schemaCacheEntry{
+		schema:  schema,
+		expires: time.Now().Add(c.ttl),
+	}
+}
+
+func (c *schemaCache) delete(namespace string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, namespace)
+}
+
+func newSchemaNode() *schemaNode {
+	return &schemaNode{
+		types:      map[string]int{},
+		arrayTypes: map[string]int{},
+		children:   map[string]*schemaNode{},
+	}
+}
+
+// inferCollectionSchema samples up to sampleSize documents of the scope from
+// the collection and infers the shape of its documents.
+func inferCollectionSchema(ctx context.Context, collection *mongo.Collection, scope bson.M, sampleSize int64) (*MongoDBCollectionSchema, error) {
+	if sampleSize <= 0 {
+		sampleSize = defaultSchemaSampleSize
+	}
+	if sampleSize > maxSchemaSampleSize {
+		sampleSize = maxSchemaSampleSize
+	}
+
+	cursor, err := collection.Aggregate(ctx, scopedSample(scope, sampleSize))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	root := newSchemaNode()
+	for cursor.Next(ctx) {
+		if err := root.addDocument(cursor.Current); err != nil {
+			return nil, err
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	schema := &MongoDBCollectionSchema{
+		SampleSize:     root.objectCount,
+		Fields:         []MongoDBSchemaField{},
+		ObjectIDFields: []string{},
+		DateFields:     []string{},
+		JSONSchema:     root.jsonSchema(),
+	}
+	schema.JSONSchema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	schema.JSONSchema["title"] = collection.Name()
+
+	root.collectFields("", schema)
+
+	return schema, nil
+}
+
+func (n *schemaNode) addDocument(doc bson.Raw) error {
+	n.objectCount++
+
+	elements, err := doc.Elements()
+	if err != nil {
+		return err
+	}
+	for _, element := range elements {
+		child, ok := n.children[element.Key()]
+		if !ok {
+			child = newSchemaNode()
+			n.children[element.Key()] = child
+		}
+		child.count++
+		if err := child.addValue(element.Value()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (n *schemaNode) addValue(value bson.RawValue) error {
+	n.types[bsonTypeAlias(value.Type)]++
+
+	switch value.Type {
+	case bson.TypeEmbeddedDocument:
+		return n.addDocument(value.Document())
+	case bson.TypeArray:
+		values, err := value.Array().Values()
+		if err != nil {
+			return err
+		}
+		// Elements of nested arrays are not inspected, they are only
+		// reported as arrays.
+		for _, element := range values {
+			n.arrayTypes[bsonTypeAlias(element.Type)]++
+			switch element.Type {
+			case bson.TypeEmbeddedDocument:
+				if err := n.addDocument(element.Document()); err != nil {
+					return err
+				}
+			case bson.TypeArray:
+			default:
+				n.addScalar(element)
+			}
+		}
+		return nil
+	}
+
+	n.addScalar(value)
+	return nil
+}
+
+func (n *schemaNode) addScalar(value bson.RawValue) {
+	if str, ok := value.StringValueOK(); ok {
+		n.strings++
+		if objectIDStringPattern.MatchString(str) {
+			n.oidStrings++
+		}
+		if _, err := time.Parse(time.RFC3339, str); err == nil {
+			n.dateStrings++
+		}
+	}
+
+	if len(n.examples) >= maxSchemaExamples {
+		return
+	}
+	example := value.String()
+	if len(example) > maxSchemaExampleLength {
+		// Cut on a rune boundary to keep the example valid UTF-8.
+		cut := maxSchemaExampleLength
+		for cut > 0 && !utf8.RuneStart(example[cut]) {
+			cut--
+		}
+		example = example[:cut] + "..."
+	}
+	for _, existing := range n.examples {
+		if existing == example {
+			return
+		}
+	}
+	n.examples = append(n.examples, example)
+}
+
+func (n *schemaNode) collectFields(prefix string, schema *MongoDBCollectionSchema) {
+	keys := make([]string, 0, len(n.children))
+	for key := range n.children {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		child := n.children[key]
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+
+		field := MongoDBSchemaField{
+			Path:              path,
+			Types:             typeFrequencies(child.types),
+			PresenceRatio:     ratio(child.count, n.objectCount),
+			ArrayElementTypes: typeFrequencies(child.arrayTypes),
+			Examples:          child.examples,
+			IsObjectID:        child.types["objectId"] > 0 || (child.strings > 0 && child.oidStrings == child.strings),
+			IsDate:            child.types["date"] > 0 || (child.strings > 0 && child.dateStrings == child.strings),
+		}
+		if len(field.ArrayElementTypes) == 0 {
+			field.ArrayElementTypes = nil
+		}
+
+		schema.Fields = append(schema.Fields, field)
+		if field.IsObjectID {
+			schema.ObjectIDFields = append(schema.ObjectIDFields, path)
+		}
+		if field.IsDate {
+			schema.DateFields = append(schema.DateFields, path)
+		}
+
+		child.collectFields(path, schema)
+	}
+}
+
+func (n *schemaNode) jsonSchema() map[string]any {
+	properties := map[string]any{}
+	required := []string{}
+	for key, child := range n.children {
+		properties[key] = child.fieldJSONSchema()
+		if child.count == n.objectCount {
+			required = append(required, key)
+		}
+	}
+	sort.Strings(required)
+
+	schema := map[string]any{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+
+	return schema
+}
+
+func (n *schemaNode) fieldJSONSchema() map[string]any {
+	schema := typedJSONSchema(n.types)
+	target := schema
+
+	// Scalars and documents found inside arrays describe the array items.
+	if len(n.arrayTypes) > 0 {
+		target = typedJSONSchema(n.arrayTypes)
+		schema["items"] = target
+	}
+	if len(n.children) > 0 {
+		for key, value := range n.jsonSchema() {
+			if key != "type" {
+				target[key] = value
+			}
+		}
+	}
+	if len(n.examples) > 0 {
+		target["examples"] = n.examples
+	}
+
+	return schema
+}
+
+func typedJSONSchema(types map[string]int) map[string]any {
+	jsonTypes := []string{}
+	bsonTypes := []string{}
+	seen := map[string]bool{}
+	for _, frequency := range typeFrequencies(types) {
+		bsonTypes = append(bsonTypes, frequency.Type)
+		jsonType := jsonSchemaType(frequency.Type)
+		if !seen[jsonType] {
+			seen[jsonType] = true
+			jsonTypes = append(jsonTypes, jsonType)
+		}
+	}
+
+	schema := map[string]any{}
+	if len(jsonTypes) == 1 {
+		schema["type"] = jsonTypes[0]
+	} else {
+		schema["type"] = jsonTypes
+	}
+	if len(bsonTypes) == 1 {
+		schema["bsonType"] = bsonTypes[0]
+	} else {
+		schema["bsonType"] = bsonTypes
+	}
+	if types["date"] > 0 || types["timestamp"] > 0 {
+		schema["format"] = "date-time"
+	}
+
+	return schema
+}
+
+func typeFrequencies(types map[string]int) []MongoDBSchemaTypeFrequency {
+	total := 0
+	for _, count := range types {
+		total += count
+	}
+
+	frequencies := []MongoDBSchemaTypeFrequency{}
+	for name, count := range types {
+		frequencies = append(frequencies, MongoDBSchemaTypeFrequency{
+			Type:      name,
+			Count:     count,
+			Frequency: ratio(count, total),
+		})
+	}
+	sort.Slice(frequencies, func(i, j int) bool {
+		if frequencies[i].Count != frequencies[j].Count {
+			return frequencies[i].Count > frequencies[j].Count
+		}
+		return frequencies[i].Type < frequencies[j].Type
+	})
+
+	return frequencies
+}
+
+func ratio(count, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(count) / float64(total)
+}
+
+// bsonTypeAlias returns the alias of the BSON type as accepted by $type.
+func bsonTypeAlias(t bson.Type) string {
+	switch t {
+	case bson.TypeDouble:
+		return "double"
+	case bson.TypeString:
+		return "string"
+	case bson.TypeEmbeddedDocument:
+		return "object"
+	case bson.TypeArray:
+		return "array"
+	case bson.TypeBinary:
+		return "binData"
+	case bson.TypeUndefined:
+		return "undefined"
+	case bson.TypeObjectID:
+		return "objectId"
+	case bson.TypeBoolean:
+		return "bool"
+	case bson.TypeDateTime:
+		return "date"
+	case bson.TypeNull:
+		return "null"
+	case bson.TypeRegex:
+		return "regex"
+	case bson.TypeDBPointer:
+		return "dbPointer"
+	case bson.TypeJavaScript:
+		return "javascript"
+	case bson.TypeSymbol:
+		return "symbol"
+	case bson.TypeCodeWithScope:
+		return "javascriptWithScope"
+	case bson.TypeInt32:
+		return "int"
+	case bson.TypeTimestamp:
+		return "timestamp"
+	case bson.TypeInt64:
+		return "long"
+	case bson.TypeDecimal128:
+		return "decimal"
+	case bson.TypeMinKey:
+		return "minKey"
+	case bson.TypeMaxKey:
+		return "maxKey"
+	}
+	return strings.ToLower(t.String())
+}
+
+func jsonSchemaType(bsonType string) string {
+	switch bsonType {
+	case "double", "decimal":
+		return "number"
+	case "int", "long":
+		return "integer"
+	case "bool":
+		return "boolean"
+	case "object":
+		return "object"
+	case "array":
+		return "array"
+	case "null", "undefined":
+		return "null"
+	}
+	return "string"
+}
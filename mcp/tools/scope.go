@@ -0,0 +1,889 @@
+package tools
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// firstStages are the stages that must come first in a pipeline, the scope
+// $match is inserted right after them.
+var firstStages = []string{"$changeStream", "$geoNear", "$search", "$vectorSearch"}
+
+// unscopedStages are the first stages whose output is not the documents of
+// the collection, the pipelines starting with them are not scoped at their
+// top level.
+var unscopedStages = []string{"$collStats", "$indexStats", "$documents", "$searchMeta"}
+
+// scopeEventTypes are the change events without documents, kept by the
+// scope of change streams.
+var scopeEventTypes = bson.A{"drop", "dropDatabase", "rename", "invalidate"}
+
+// scopePolicy restricts the tools to the documents matching a scope filter,
+// e.g. the documents of a tenant. The filter is ANDed into the filters of the
+// tools, prepended to their pipelines and set on the inserted documents. It
+// is the SCOPE_FILTER of the server, ANDed with the scope of the session
+// given in the _meta of its initialize request when SCOPE_SESSION_META_KEY is
+// set.
+type scopePolicy struct {
+	filter     bson.M
+	sessionKey string
+	// collections are "database.collection" globs, all collections when
+	// empty.
+	collections []string
+}
+
+type scopeError struct {
+	Reason string `json:"reason"`
+	Field  string `json:"field,omitempty"`
+	Path   string `json:"path,omitempty"`
+}
+
+func (e *scopeError) message() string {
+	return "Out of scope: " + e.Reason + "."
+}
+
+func (e *scopeError) Error() string {
+	message := e.message()
+
+	details, err := json.Marshal(e)
+	if err != nil {
+		return message
+	}
+	return message + "\n\n" + string(details)
+}
+
+// parseScopeFilter parses a scope filter given as an extended JSON document.
+// Only field conditions and the $and, $or and $nor operators are allowed, so
+// that the filter can also be matched against change events.
+func parseScopeFilter(value []byte) (bson.M, error) {
+	var filter bson.M
+	if err := bson.UnmarshalExtJSON(value, false, &filter); err != nil {
+		return nil, err
+	}
+	if len(filter) == 0 {
+		return nil, fmt.Errorf("the scope filter must not be empty")
+	}
+	if err := checkScopeFilter(filter); err != nil {
+		return nil, err
+	}
+	return filter, nil
+}
+
+func checkScopeFilter(filter map[string]any) error {
+	for key, value := range filter {
+		switch key {
+		case "$and", "$or", "$nor":
+			clauses, ok := pipelineStages(value)
+			if !ok {
+				return fmt.Errorf("%s must be an array", key)
+			}
+			for _, clause := range clauses {
+				document, ok := pipelineDocument(clause)
+				if !ok {
+					return fmt.Errorf("the clauses of %s must be documents", key)
+				}
+				if err := checkScopeFilter(document); err != nil {
+					return err
+				}
+			}
+		default:
+			if strings.HasPrefix(key, "$") {
+				return fmt.Errorf("the %s operator is not allowed at the top level of a scope filter", key)
+			}
+		}
+	}
+	return nil
+}
+
+// scoped reports whether the namespace is restricted by the policy.
+func (p *scopePolicy) scoped(namespace string) bool {
+	if p == nil || (p.filter == nil && p.sessionKey == "") {
+		return false
+	}
+	if len(p.collections) == 0 {
+		return true
+	}
+	for _, pattern := range p.collections {
+		if matched, _ := path.Match(pattern, namespace); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// sessionScope returns the scope filter of the session.
+func (p *scopePolicy) sessionScope(session *mcp.ServerSession) (bson.M, error) {
+	var value any
+	if session != nil {
+		if params := session.InitializeParams(); params != nil {
+			value = params.Meta[p.sessionKey]
+		}
+	}
+	if value == nil {
+		return nil, &scopeError{
+			Reason: fmt.Sprintf("the session has no scope, its initialize request must set %s in _meta", p.sessionKey),
+		}
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	filter, err := parseScopeFilter(data)
+	if err != nil {
+		return nil, &scopeError{
+			Reason: fmt.Sprintf("the scope of the session is invalid: %s", err.Error()),
+		}
+	}
+	return filter, nil
+}
+
+// resolve returns the scope filter of the namespace for the session, nil
+// when the namespace is not scoped.
+func (p *scopePolicy) resolve(session *mcp.ServerSession, namespace string) (bson.M, error) {
+	if !p.scoped(namespace) {
+		return nil, nil
+	}
+	if p.sessionKey == "" {
+		return p.filter, nil
+	}
+
+	sessionFilter, err := p.sessionScope(session)
+	if err != nil {
+		return nil, err
+	}
+	if p.filter == nil {
+		return sessionFilter, nil
+	}
+	return bson.M{"$and": bson.A{p.filter, sessionFilter}}, nil
+}
+
+// resolveDatabase returns the scope filter of the collections of the
+// database, for the change streams of a whole database. The databases are
+// refused when the scope only applies to some collections.
+func (p *scopePolicy) resolveDatabase(session *mcp.ServerSession, database string) (bson.M, error) {
+	if p == nil || (p.filter == nil && p.sessionKey == "") {
+		return nil, nil
+	}
+	if len(p.collections) > 0 {
+		return nil, &scopeError{
+			Reason: "the scope applies to some collections only, watch a single collection instead of the database",
+		}
+	}
+	return p.resolve(session, database+".*")
+}
+
+// key identifies the scope of the session, for the state shared between
+// the sessions of a same scope such as change streams.
+func (p *scopePolicy) key(session *mcp.ServerSession, namespace string) (string, error) {
+	if !p.scoped(namespace) || p.sessionKey == "" {
+		return "", nil
+	}
+	scope, err := p.sessionScope(session)
+	if err != nil {
+		return "", err
+	}
+	data, err := bson.MarshalExtJSON(scope, true, false)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// scopedSample returns the stages sampling the documents of the scope.
+func scopedSample(scope bson.M, sampleSize int64) []bson.M {
+	sample := bson.M{"$sample": bson.M{"size": sampleSize}}
+	if scope == nil {
+		return []bson.M{sample}
+	}
+	return []bson.M{{"$match": scope}, sample}
+}
+
+// scopedFilter returns the filter ANDed with the scope.
+func scopedFilter(scope, filter bson.M) bson.M {
+	if scope == nil {
+		return filter
+	}
+	if len(filter) == 0 {
+		return bson.M{"$and": bson.A{scope}}
+	}
+	return bson.M{"$and": bson.A{filter, scope}}
+}
+
+// pipeline returns the pipeline of an aggregation on the collection of the
+// database restricted to the scope of the session: the scope is matched
+// first, and in the sub-pipelines of the stages reading other collections.
+func (p *scopePolicy) pipeline(session *mcp.ServerSession, database, collection string, pipeline []bson.M) ([]bson.M, error) {
+	if p == nil || (p.filter == nil && p.sessionKey == "") {
+		return pipeline, nil
+	}
+
+	stages := make([]any, 0, len(pipeline))
+	for _, stage := range pipeline {
+		stages = append(stages, stage)
+	}
+
+	scoped, err := p.scopeStages(session, database, collection, stages, "pipeline")
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]bson.M, 0, len(scoped))
+	for _, stage := range scoped {
+		document, _ := pipelineDocument(stage)
+		result = append(result, bson.M(document))
+	}
+	return result, nil
+}
+
+func (p *scopePolicy) scopeStages(session *mcp.ServerSession, database, collection string, stages []any, stagesPath string) ([]any, error) {
+	scope, err := p.resolve(session, database+"."+collection)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]any, 0, len(stages)+1)
+	insertAt := 0
+	unscoped := false
+	match := bson.M{"$match": scope}
+	for i, value := range stages {
+		stagePath := fmt.Sprintf("%s.%d", stagesPath, i)
+		document, ok := pipelineDocument(value)
+		if !ok {
+			result = append(result, value)
+			continue
+		}
+
+		stage := bson.M{}
+		for name, spec := range document {
+			if i == 0 && containsName(firstStages, name) {
+				insertAt = 1
+			}
+			if i == 0 && name == "$changeStream" && scope != nil {
+				match = changeStreamMatch(scope)
+			}
+			if i == 0 && containsName(unscopedStages, name) {
+				unscoped = true
+			}
+			if containsName(writeStages, name) {
+				// The documents written are neither restricted to the
+				// scope of the target nor given its fields.
+				target, err := p.resolve(session, writeNamespace(database, name, spec))
+				if err != nil {
+					return nil, err
+				}
+				if scope != nil || target != nil {
+					return nil, &scopeError{
+						Reason: fmt.Sprintf("the %s stage is not allowed on a scoped collection", name),
+						Path:   stagePath + "." + name,
+					}
+				}
+			}
+
+			scoped, err := p.scopeStage(session, database, name, spec, stagePath+"."+name)
+			if err != nil {
+				return nil, err
+			}
+			stage[name] = scoped
+		}
+		result = append(result, stage)
+	}
+
+	if scope == nil || unscoped {
+		return result, nil
+	}
+	if insertAt > len(result) {
+		insertAt = len(result)
+	}
+	result = append(result[:insertAt], append([]any{match}, result[insertAt:]...)...)
+	return result, nil
+}
+
+// writeNamespace returns the "database.collection" namespace written by a
+// $out or $merge stage, the collection of the database when the stage only
+// names a collection.
+func writeNamespace(database, name string, spec any) string {
+	target := spec
+	if name == "$merge" {
+		if document, ok := pipelineDocument(spec); ok {
+			target = document["into"]
+		}
+	}
+	if coll, ok := target.(string); ok {
+		return database + "." + coll
+	}
+
+	document, ok := pipelineDocument(target)
+	if !ok {
+		return database + "."
+	}
+	if db, ok := document["db"].(string); ok && db != "" {
+		database = db
+	}
+	coll, _ := document["coll"].(string)
+	return database + "." + coll
+}
+
+// scopeStage scopes the sub-pipelines of a stage reading other collections.
+func (p *scopePolicy) scopeStage(session *mcp.ServerSession, database, name string, spec any, stagePath string) (any, error) {
+	switch name {
+	case "$lookup", "$unionWith":
+		document, ok := pipelineDocument(spec)
+		if !ok {
+			coll, ok := spec.(string)
+			if name != "$unionWith" || !ok {
+				return spec, nil
+			}
+			document = map[string]any{"coll": coll}
+		}
+		scoped := bson.M{}
+		for key, value := range document {
+			scoped[key] = value
+		}
+
+		fromKey := "from"
+		if name == "$unionWith" {
+			fromKey = "coll"
+		}
+		fromDatabase, fromCollection := database, ""
+		if from, ok := pipelineDocument(scoped[fromKey]); ok {
+			if db, ok := from["db"].(string); ok && db != "" {
+				fromDatabase = db
+			}
+			fromCollection, _ = from["coll"].(string)
+		} else {
+			fromCollection, _ = scoped[fromKey].(string)
+		}
+
+		stages, _ := pipelineStages(scoped["pipeline"])
+		if fromCollection == "" {
+			// A $lookup on the documents of its pipeline, such as
+			// $documents.
+			if stages == nil {
+				return spec, nil
+			}
+			pipeline, err := p.scopeSubPipeline(session, database, stages, stagePath+".pipeline")
+			if err != nil {
+				return nil, err
+			}
+			scoped["pipeline"] = pipeline
+			return scoped, nil
+		}
+
+		pipeline, err := p.scopeStages(session, fromDatabase, fromCollection, stages, stagePath+".pipeline")
+		if err != nil {
+			return nil, err
+		}
+		if stages == nil && len(pipeline) == 0 {
+			// An unscoped collection, the stage is kept as is for the
+			// servers refusing a pipeline alongside localField.
+			return spec, nil
+		}
+		scoped["pipeline"] = bson.A(pipeline)
+		return scoped, nil
+	case "$graphLookup":
+		document, ok := pipelineDocument(spec)
+		if !ok {
+			return spec, nil
+		}
+		from, _ := document["from"].(string)
+		scope, err := p.resolve(session, database+"."+from)
+		if err != nil || scope == nil {
+			return spec, err
+		}
+
+		scoped := bson.M{}
+		for key, value := range document {
+			scoped[key] = value
+		}
+		restrict, _ := pipelineDocument(document["restrictSearchWithMatch"])
+		scoped["restrictSearchWithMatch"] = scopedFilter(scope, restrict)
+		return scoped, nil
+	case "$facet":
+		document, ok := pipelineDocument(spec)
+		if !ok {
+			return spec, nil
+		}
+		scoped := bson.M{}
+		for facet, value := range document {
+			stages, ok := pipelineStages(value)
+			if !ok {
+				scoped[facet] = value
+				continue
+			}
+			pipeline, err := p.scopeSubPipeline(session, database, stages, stagePath+"."+facet)
+			if err != nil {
+				return nil, err
+			}
+			scoped[facet] = pipeline
+		}
+		return scoped, nil
+	}
+
+	return spec, nil
+}
+
+// scopeSubPipeline scopes the stages of a sub-pipeline reading the documents
+// of its parent, such as the pipelines of $facet.
+func (p *scopePolicy) scopeSubPipeline(session *mcp.ServerSession, database string, stages []any, stagesPath string) (bson.A, error) {
+	result := bson.A{}
+	for i, value := range stages {
+		document, ok := pipelineDocument(value)
+		if !ok {
+			result = append(result, value)
+			continue
+		}
+		stage := bson.M{}
+		for name, spec := range document {
+			scoped, err := p.scopeStage(session, database, name, spec, fmt.Sprintf("%s.%d.%s", stagesPath, i, name))
+			if err != nil {
+				return nil, err
+			}
+			stage[name] = scoped
+		}
+		result = append(result, stage)
+	}
+	return result, nil
+}
+
+func containsName(names []string, name string) bool {
+	for _, candidate := range names {
+		if candidate == name {
+			return true
+		}
+	}
+	return false
+}
+
+// changeStreamMatch returns the $match stage restricting the change events
+// to the documents of the scope, keeping the events without documents.
+func changeStreamMatch(scope bson.M) bson.M {
+	return bson.M{"$match": bson.M{"$or": bson.A{
+		prefixedFilter(scope, "fullDocument."),
+		bson.M{"operationType": bson.M{"$in": scopeEventTypes}},
+	}}}
+}
+
+// prefixedFilter returns the scope filter matching the fields under the
+// prefix, e.g. the fullDocument of a change event.
+func prefixedFilter(filter map[string]any, prefix string) bson.M {
+	prefixed := bson.M{}
+	for key, value := range filter {
+		if !strings.HasPrefix(key, "$") {
+			prefixed[prefix+key] = value
+			continue
+		}
+		clauses, _ := pipelineStages(value)
+		prefixedClauses := bson.A{}
+		for _, clause := range clauses {
+			document, _ := pipelineDocument(clause)
+			prefixedClauses = append(prefixedClauses, prefixedFilter(document, prefix))
+		}
+		prefixed[key] = prefixedClauses
+	}
+	return prefixed
+}
+
+// scopeFields returns the fields the scope filter applies to.
+func scopeFields(filter map[string]any) []string {
+	fields := []string{}
+	for key, value := range filter {
+		if !strings.HasPrefix(key, "$") {
+			fields = append(fields, key)
+			continue
+		}
+		clauses, _ := pipelineStages(value)
+		for _, clause := range clauses {
+			document, _ := pipelineDocument(clause)
+			fields = append(fields, scopeFields(document)...)
+		}
+	}
+	return fields
+}
+
+// scopeValues returns the fields the scope filter requires to be equal to a
+// value, at its top level or in its $and clauses.
+func scopeValues(filter map[string]any) map[string]any {
+	values := map[string]any{}
+	for key, value := range filter {
+		switch {
+		case key == "$and":
+			clauses, _ := pipelineStages(value)
+			for _, clause := range clauses {
+				document, _ := pipelineDocument(clause)
+				for field, value := range scopeValues(document) {
+					values[field] = value
+				}
+			}
+		case strings.HasPrefix(key, "$"):
+		default:
+			condition, ok := pipelineDocument(value)
+			if !ok {
+				values[key] = value
+				continue
+			}
+			if equal, ok := condition["$eq"]; ok && len(condition) == 1 {
+				values[key] = equal
+			} else if !operatorDocument(condition) {
+				values[key] = value
+			}
+		}
+	}
+	return values
+}
+
+// operatorDocument reports whether the document is a query operator
+// document, such as {"$in": [...]}.
+func operatorDocument(document map[string]any) bool {
+	for key := range document {
+		if strings.HasPrefix(key, "$") {
+			return true
+		}
+	}
+	return false
+}
+
+// sameValue reports whether two values are equal, comparing the numbers by
+// value whatever their type as MongoDB does.
+func sameValue(a, b any) bool {
+	if x, ok := numberValue(a); ok {
+		y, ok := numberValue(b)
+		return ok && x == y
+	}
+
+	left, err := bson.MarshalExtJSON(bson.M{"v": a}, true, false)
+	if err != nil {
+		return false
+	}
+	right, err := bson.MarshalExtJSON(bson.M{"v": b}, true, false)
+	if err != nil {
+		return false
+	}
+	return string(left) == string(right)
+}
+
+func numberValue(value any) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+// documentField returns the value of the dotted field path in the document.
+func documentField(document map[string]any, field string) (any, bool) {
+	var value any = document
+	for _, segment := range strings.Split(field, ".") {
+		nested, ok := pipelineDocument(value)
+		if !ok {
+			return nil, false
+		}
+		if value, ok = nested[segment]; !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// equalField reports whether the value of a field, or one of its elements
+// for an array, equals the value of a condition. A null condition also
+// matches the missing fields.
+func equalField(value any, exists bool, condition any) bool {
+	if condition == nil {
+		return !exists || value == nil
+	}
+	if !exists {
+		return false
+	}
+	if sameValue(value, condition) {
+		return true
+	}
+	if elements, ok := pipelineStages(value); ok {
+		for _, element := range elements {
+			if sameValue(element, condition) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matchesScope reports whether the document matches the scope filter, with
+// the field or clause it does not match. Only the equalities, the $eq, $ne,
+// $in, $nin and $exists conditions and the $and, $or and $nor clauses can be
+// checked, the other conditions return a *scopeError.
+func matchesScope(filter map[string]any, document map[string]any) (bool, string, error) {
+	for key, value := range filter {
+		switch key {
+		case "$and", "$or", "$nor":
+			clauses, _ := pipelineStages(value)
+			matched := 0
+			for _, clause := range clauses {
+				clauseFilter, _ := pipelineDocument(clause)
+				ok, _, err := matchesScope(clauseFilter, document)
+				if err != nil {
+					return false, "", err
+				}
+				if ok {
+					matched++
+				}
+			}
+			if (key == "$and" && matched < len(clauses)) || (key == "$or" && matched == 0) || (key == "$nor" && matched > 0) {
+				return false, key, nil
+			}
+			continue
+		}
+
+		fieldValue, exists := documentField(document, key)
+		condition, ok := pipelineDocument(value)
+		if !ok || !operatorDocument(condition) {
+			if !equalField(fieldValue, exists, value) {
+				return false, key, nil
+			}
+			continue
+		}
+		for operator, argument := range condition {
+			var ok bool
+			switch operator {
+			case "$eq":
+				ok = equalField(fieldValue, exists, argument)
+			case "$ne":
+				ok = !equalField(fieldValue, exists, argument)
+			case "$in", "$nin":
+				values, _ := pipelineStages(argument)
+				for _, candidate := range values {
+					if equalField(fieldValue, exists, candidate) {
+						ok = true
+						break
+					}
+				}
+				if operator == "$nin" {
+					ok = !ok
+				}
+			case "$exists":
+				ok = exists == truthy(argument)
+			default:
+				return false, key, &scopeError{
+					Reason: fmt.Sprintf("the %s condition of the scope on %s cannot be checked on the written documents", operator, key),
+					Field:  key,
+				}
+			}
+			if !ok {
+				return false, key, nil
+			}
+		}
+	}
+	return true, "", nil
+}
+
+// truthy reports whether the value is true for a query operator such as
+// $exists, which takes booleans and numbers.
+func truthy(value any) bool {
+	if number, ok := numberValue(value); ok {
+		return number != 0
+	}
+	boolean, _ := value.(bool)
+	return boolean
+}
+
+// checkScopeMatch refuses the written documents not matching the scope, the
+// path naming the document in the tool input.
+func checkScopeMatch(scope bson.M, document map[string]any, documentPath, reason string) error {
+	ok, field, err := matchesScope(scope, document)
+	if err != nil {
+		var scopeErr *scopeError
+		if errors.As(err, &scopeErr) {
+			scopeErr.Path = documentPath
+		}
+		return err
+	}
+	if ok {
+		return nil
+	}
+
+	path := documentPath
+	if !strings.HasPrefix(field, "$") {
+		path += "." + field
+	}
+	return &scopeError{
+		Reason: fmt.Sprintf(reason, field),
+		Field:  field,
+		Path:   path,
+	}
+}
+
+// scopeUpsert refuses the upserts whose filter would insert a document
+// outside of the scope. The inserted document takes the fields the filter
+// and the scope require to be equal to a value, the updates of the scope
+// fields being refused by scopeUpdate.
+func scopeUpsert(scope bson.M, filter bson.M, filterPath string) error {
+	if scope == nil {
+		return nil
+	}
+
+	document := bson.M{}
+	for _, values := range []map[string]any{scopeValues(filter), scopeValues(scope)} {
+		for field, value := range values {
+			parent := document
+			segments := strings.Split(field, ".")
+			for _, segment := range segments[:len(segments)-1] {
+				child, ok := parent[segment].(bson.M)
+				if !ok {
+					child = bson.M{}
+					parent[segment] = child
+				}
+				parent = child
+			}
+			parent[segments[len(segments)-1]] = value
+		}
+	}
+	return checkScopeMatch(scope, document, filterPath, "the upserted document would not match the scope condition %s of the session, set the scope fields in the filter")
+}
+
+// scopeDocument sets the fields required by the scope on a document to
+// insert or a replacement, refusing the documents of another scope. The
+// path names the document in the tool input.
+func scopeDocument(scope bson.M, document bson.M, documentPath string) error {
+	if scope == nil || document == nil {
+		return nil
+	}
+
+	for field, value := range scopeValues(scope) {
+		parent := map[string]any(document)
+		segments := strings.Split(field, ".")
+		for _, segment := range segments[:len(segments)-1] {
+			nested, ok := parent[segment]
+			if !ok {
+				child := bson.M{}
+				parent[segment] = child
+				parent = child
+				continue
+			}
+			child, ok := pipelineDocument(nested)
+			if !ok {
+				return &scopeError{
+					Reason: fmt.Sprintf("the field %s must be a document holding the scope field %s", segment, field),
+					Field:  field,
+					Path:   documentPath + "." + field,
+				}
+			}
+			if _, isD := nested.(bson.D); isD {
+				// The fields of a bson.D copy would be lost.
+				child = bson.M(child)
+				parent[segment] = child
+			}
+			parent = child
+		}
+
+		last := segments[len(segments)-1]
+		existing, ok := parent[last]
+		if !ok {
+			parent[last] = value
+			continue
+		}
+		if !sameValue(existing, value) {
+			return &scopeError{
+				Reason: fmt.Sprintf("the field %s is outside of the scope of the session", field),
+				Field:  field,
+				Path:   documentPath + "." + field,
+			}
+		}
+	}
+	return checkScopeMatch(scope, document, documentPath, "the document does not match the scope condition %s of the session")
+}
+
+// updatesScopeField reports whether the update of the field changes one of
+// the scope fields.
+func updatesScopeField(fields []string, field string) (string, bool) {
+	for _, scopeField := range fields {
+		if field == scopeField || strings.HasPrefix(scopeField, field+".") || strings.HasPrefix(field, scopeField+".") {
+			return scopeField, true
+		}
+	}
+	return "", false
+}
+
+// scopeUpdate refuses the updates changing the scope fields, which would
+// move the documents out of the scope of the session.
+func scopeUpdate(scope bson.M, update any, updatePath string) error {
+	if scope == nil {
+		return nil
+	}
+	fields := scopeFields(scope)
+
+	refuse := func(scopeField, path string) error {
+		return &scopeError{
+			Reason: fmt.Sprintf("the update changes the scope field %s", scopeField),
+			Field:  scopeField,
+			Path:   path,
+		}
+	}
+
+	if document, ok := pipelineDocument(update); ok {
+		for operator, value := range document {
+			fieldsDocument, ok := pipelineDocument(value)
+			if !ok {
+				continue
+			}
+			for field, target := range fieldsDocument {
+				if scopeField, ok := updatesScopeField(fields, field); ok {
+					return refuse(scopeField, updatePath+"."+operator+"."+field)
+				}
+				if target, ok := target.(string); ok && operator == "$rename" {
+					if scopeField, ok := updatesScopeField(fields, target); ok {
+						return refuse(scopeField, updatePath+"."+operator+"."+field)
+					}
+				}
+			}
+		}
+		return nil
+	}
+
+	stages, ok := update.([]bson.M)
+	if !ok {
+		return nil
+	}
+	for i, stage := range stages {
+		for name, spec := range stage {
+			stagePath := fmt.Sprintf("%s.%d.%s", updatePath, i, name)
+			switch name {
+			case "$set", "$addFields":
+				document, _ := pipelineDocument(spec)
+				for field := range document {
+					if scopeField, ok := updatesScopeField(fields, field); ok {
+						return refuse(scopeField, stagePath+"."+field)
+					}
+				}
+			case "$unset":
+				unset, ok := pipelineStages(spec)
+				if !ok {
+					unset = []any{spec}
+				}
+				for _, value := range unset {
+					field, _ := value.(string)
+					if scopeField, ok := updatesScopeField(fields, field); ok {
+						return refuse(scopeField, stagePath)
+					}
+				}
+			case "$project", "$replaceRoot", "$replaceWith":
+				return &scopeError{
+					Reason: fmt.Sprintf("the %s stage is not allowed in the update of a scoped collection", name),
+					Path:   stagePath,
+				}
+			}
+		}
+	}
+	return nil
+}
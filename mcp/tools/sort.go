@@ -0,0 +1,40 @@
+package tools
+
+import (
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type MongoDBSortKey struct {
+	Field     string `json:"field" jsonschema:"The field to sort by"`
+	Direction int    `json:"direction" jsonschema:"The sort direction, 1 for ascending or -1 for descending"`
+}
+
+// sortDocument converts the ordered sort keys into a sort document.
+func sortDocument(keys []MongoDBSortKey) (bson.D, error) {
+	document := bson.D{}
+	for _, key := range keys {
+		if key.Field == "" {
+			return nil, fmt.Errorf("Sort field cannot be empty")
+		}
+		if key.Direction != 1 && key.Direction != -1 {
+			return nil, fmt.Errorf("Invalid sort direction %d for field %s, use 1 or -1", key.Direction, key.Field)
+		}
+		document = append(document, bson.E{Key: key.Field, Value: int32(key.Direction)})
+	}
+	return document, nil
+}
+
+// sortKeys converts a sort document into ordered sort keys.
+func sortKeys(document bson.D) []MongoDBSortKey {
+	keys := []MongoDBSortKey{}
+	for _, element := range document {
+		direction := 1
+		if number, ok := indexNumber(element.Value); ok && number < 0 {
+			direction = -1
+		}
+		keys = append(keys, MongoDBSortKey{Field: element.Key, Direction: direction})
+	}
+	return keys
+}
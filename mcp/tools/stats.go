@@ -0,0 +1,186 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+type MongoDBSize struct {
+	Bytes int64  `json:"bytes" jsonschema:"The size in bytes"`
+	Human string `json:"human" jsonschema:"The size in a human readable format"`
+}
+
+type MongoDBIndexSize struct {
+	Name string      `json:"name" jsonschema:"The name of the index"`
+	Size MongoDBSize `json:"size" jsonschema:"The size of the index"`
+}
+
+type MongoDBCollectionStats struct {
+	Namespace           string             `json:"namespace" jsonschema:"The namespace of the collection"`
+	Count               int64              `json:"count" jsonschema:"The number of documents in the collection"`
+	DataSize            MongoDBSize        `json:"data_size" jsonschema:"The uncompressed size of the documents"`
+	StorageSize         MongoDBSize        `json:"storage_size" jsonschema:"The size of the storage allocated for the documents"`
+	AverageDocumentSize MongoDBSize        `json:"average_document_size" jsonschema:"The average size of a document"`
+	TotalIndexSize      MongoDBSize        `json:"total_index_size" jsonschema:"The size of all indexes"`
+	TotalSize           MongoDBSize        `json:"total_size" jsonschema:"The storage size of the documents and the indexes"`
+	IndexCount          int64              `json:"index_count" jsonschema:"The number of indexes"`
+	IndexSizes          []MongoDBIndexSize `json:"index_sizes" jsonschema:"The size of each index, largest first"`
+	Capped              bool               `json:"capped" jsonschema:"Whether the collection is capped"`
+	Shards              int                `json:"shards" jsonschema:"The number of shards the statistics were collected from"`
+}
+
+type MongoDBDatabaseStats struct {
+	Database            string      `json:"database" jsonschema:"The name of the database"`
+	Collections         int64       `json:"collections" jsonschema:"The number of collections"`
+	Views               int64       `json:"views" jsonschema:"The number of views"`
+	Objects             int64       `json:"objects" jsonschema:"The number of documents across all collections"`
+	DataSize            MongoDBSize `json:"data_size" jsonschema:"The uncompressed size of the documents"`
+	StorageSize         MongoDBSize `json:"storage_size" jsonschema:"The size of the storage allocated for the documents"`
+	AverageDocumentSize MongoDBSize `json:"average_document_size" jsonschema:"The average size of a document"`
+	Indexes             int64       `json:"indexes" jsonschema:"The number of indexes across all collections"`
+	IndexSize           MongoDBSize `json:"index_size" jsonschema:"The size of all indexes"`
+	TotalSize           MongoDBSize `json:"total_size" jsonschema:"The storage size of the documents and the indexes"`
+	FsUsedSize          MongoDBSize `json:"fs_used_size" jsonschema:"The used size of the file system the database is stored on"`
+	FsTotalSize         MongoDBSize `json:"fs_total_size" jsonschema:"The total size of the file system the database is stored on"`
+}
+
+func newSize(bytes int64) MongoDBSize {
+	return MongoDBSize{
+		Bytes: bytes,
+		Human: formatBytes(bytes),
+	}
+}
+
+// formatBytes formats the number of bytes with binary units, e.g. 1.5 MiB.
+func formatBytes(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+
+	value := float64(bytes)
+	units := []string{"KiB", "MiB", "GiB", "TiB", "PiB", "EiB"}
+	i := -1
+	for value >= unit && i < len(units)-1 {
+		value /= unit
+		i++
+	}
+
+	return fmt.Sprintf("%.1f %s", value, units[i])
+}
+
+// collectionStats collects the storage statistics of the collection using
+// $collStats, summed over all shards.
+func collectionStats(ctx context.Context, collection *mongo.Collection) (*MongoDBCollectionStats, error) {
+	cursor, err := collection.Aggregate(ctx, []bson.M{
+		{"$collStats": bson.M{"storageStats": bson.M{"scale": 1}, "count": bson.M{}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var shards []struct {
+		Namespace    string  `bson:"ns"`
+		Count        float64 `bson:"count"`
+		StorageStats struct {
+			Size           float64            `bson:"size"`
+			StorageSize    float64            `bson:"storageSize"`
+			TotalIndexSize float64            `bson:"totalIndexSize"`
+			TotalSize      float64            `bson:"totalSize"`
+			IndexCount     float64            `bson:"nindexes"`
+			IndexSizes     map[string]float64 `bson:"indexSizes"`
+			Capped         bool               `bson:"capped"`
+		} `bson:"storageStats"`
+	}
+	if err := cursor.All(ctx, &shards); err != nil {
+		return nil, err
+	}
+
+	var count, size, storageSize, totalIndexSize, totalSize float64
+	indexSizes := map[string]float64{}
+	stats := &MongoDBCollectionStats{
+		Namespace:  collection.Database().Name() + "." + collection.Name(),
+		IndexSizes: []MongoDBIndexSize{},
+		Shards:     len(shards),
+	}
+	for _, shard := range shards {
+		count += shard.Count
+		size += shard.StorageStats.Size
+		storageSize += shard.StorageStats.StorageSize
+		totalIndexSize += shard.StorageStats.TotalIndexSize
+		totalSize += shard.StorageStats.TotalSize
+		for name, indexSize := range shard.StorageStats.IndexSizes {
+			indexSizes[name] += indexSize
+		}
+		stats.IndexCount = int64(shard.StorageStats.IndexCount)
+		stats.Capped = shard.StorageStats.Capped
+	}
+	// Older servers do not report the total size.
+	if totalSize == 0 {
+		totalSize = storageSize + totalIndexSize
+	}
+
+	stats.Count = int64(count)
+	stats.DataSize = newSize(int64(size))
+	stats.StorageSize = newSize(int64(storageSize))
+	stats.TotalIndexSize = newSize(int64(totalIndexSize))
+	stats.TotalSize = newSize(int64(totalSize))
+	if count > 0 {
+		stats.AverageDocumentSize = newSize(int64(size / count))
+	} else {
+		stats.AverageDocumentSize = newSize(0)
+	}
+	for name, indexSize := range indexSizes {
+		stats.IndexSizes = append(stats.IndexSizes, MongoDBIndexSize{Name: name, Size: newSize(int64(indexSize))})
+	}
+	sort.Slice(stats.IndexSizes, func(i, j int) bool {
+		return stats.IndexSizes[i].Size.Bytes > stats.IndexSizes[j].Size.Bytes
+	})
+
+	return stats, nil
+}
+
+// databaseStats collects the storage statistics of the database using the
+// dbStats command.
+func databaseStats(ctx context.Context, database *mongo.Database) (*MongoDBDatabaseStats, error) {
+	var result struct {
+		Collections float64 `bson:"collections"`
+		Views       float64 `bson:"views"`
+		Objects     float64 `bson:"objects"`
+		AvgObjSize  float64 `bson:"avgObjSize"`
+		DataSize    float64 `bson:"dataSize"`
+		StorageSize float64 `bson:"storageSize"`
+		Indexes     float64 `bson:"indexes"`
+		IndexSize   float64 `bson:"indexSize"`
+		TotalSize   float64 `bson:"totalSize"`
+		FsUsedSize  float64 `bson:"fsUsedSize"`
+		FsTotalSize float64 `bson:"fsTotalSize"`
+	}
+	err := database.RunCommand(ctx, bson.D{{Key: "dbStats", Value: 1}, {Key: "scale", Value: 1}}).Decode(&result)
+	if err != nil {
+		return nil, err
+	}
+	if result.TotalSize == 0 {
+		result.TotalSize = result.StorageSize + result.IndexSize
+	}
+
+	return &MongoDBDatabaseStats{
+		Database:            database.Name(),
+		Collections:         int64(result.Collections),
+		Views:               int64(result.Views),
+		Objects:             int64(result.Objects),
+		DataSize:            newSize(int64(result.DataSize)),
+		StorageSize:         newSize(int64(result.StorageSize)),
+		AverageDocumentSize: newSize(int64(result.AvgObjSize)),
+		Indexes:             int64(result.Indexes),
+		IndexSize:           newSize(int64(result.IndexSize)),
+		TotalSize:           newSize(int64(result.TotalSize)),
+		FsUsedSize:          newSize(int64(result.FsUsedSize)),
+		FsTotalSize:         newSize(int64(result.FsTotalSize)),
+	}, nil
+}
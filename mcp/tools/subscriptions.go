@@ -0,0 +1,368 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+const (
+	// maxSubscriptionEvents is the number of recent events kept for the
+	// changes resource of a subscription.
+	maxSubscriptionEvents  = 100
+	maxSubscriptionBackoff = 30 * time.Second
+	// changeStreamHistoryLost is the server error returned when the resume
+	// token is no longer in the oplog.
+	changeStreamHistoryLost = 286
+)
+
+// changeSubscription is a change stream shared by the sessions subscribed to
+// the same resource URI within the same scope.
+type changeSubscription struct {
+	uri string
+	// key identifies the subscription and its resume token, the URI
+	// followed by the scope of its sessions when they have their own.
+	key        string
+	scoped     bool
+	kind       string
+	collection *mongo.Collection
+	pipeline   []bson.M
+	sessions   map[*mcp.ServerSession]bool
+	cancel     context.CancelFunc
+
+	mu     sync.Mutex
+	events []bson.M
+}
+
+type MongoDBCollectionChanges struct {
+	Subscribed  bool     `json:"subscribed"`
+	Events      []bson.M `json:"events"`
+	ResumeToken bson.M   `json:"resume_token,omitempty"`
+}
+
+// changePipeline returns the change stream pipeline of a subscription. The
+// filter query parameter of the URI is an extended JSON document matched
+// against the change events.
+func changePipeline(uri string, kind string, filters *filterPolicy) ([]bson.M, error) {
+	pipeline := []bson.M{}
+	if kind == "indexes" {
+		pipeline = append(pipeline, bson.M{"$match": bson.M{
+			"operationType": bson.M{"$in": bson.A{"createIndexes", "dropIndexes", "drop", "rename", "invalidate"}},
+		}})
+	}
+
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		return nil, mcp.ResourceNotFoundError(uri)
+	}
+	if filter := parsed.Query().Get("filter"); filter != "" {
+		var match bson.M
+		if err := bson.UnmarshalExtJSON([]byte(filter), false, &match); err != nil {
+			return nil, fmt.Errorf("Invalid filter, use an extended JSON document: %s", err.Error())
+		}
+		if err := filters.check(match, "filter"); err != nil {
+			return nil, err
+		}
+		pipeline = append(pipeline, bson.M{"$match": match})
+	}
+
+	return pipeline, nil
+}
+
+// openChangeStream opens the change stream of the subscription from its
+// stored resume token, or from now when there is none or it is too old.
+func (r *MongoDBCollectionResources) openChangeStream(ctx context.Context, sub *changeSubscription) (*mongo.ChangeStream, error) {
+	opts := options.ChangeStream().SetShowExpandedEvents(sub.kind == "indexes")
+	if sub.scoped {
+		opts.SetFullDocument(options.UpdateLookup)
+	}
+	token, ok := r.tool.resumeTokens.get(sub.key)
+	if ok {
+		opts.SetStartAfter(token)
+	}
+
+	stream, err := sub.collection.Watch(ctx, sub.pipeline, opts)
+	var serverErr mongo.ServerError
+	if ok && errors.As(err, &serverErr) && serverErr.HasErrorCode(changeStreamHistoryLost) {
+		log.Printf("resume token of %s expired, missed changes cannot be replayed", sub.uri)
+		if err := r.tool.resumeTokens.set(sub.key, nil); err != nil {
+			log.Printf("failed to store resume token of %s: %s", sub.uri, err.Error())
+		}
+		stream, err = sub.collection.Watch(ctx, sub.pipeline, opts.SetStartAfter(nil))
+	}
+
+	return stream, err
+}
+
+// record keeps the current event of the stream in the recent events of the
+// subscription, redacted by the policy.
+func (sub *changeSubscription) record(stream *mongo.ChangeStream, redaction *redactionPolicy) {
+	var event bson.M
+	if err := stream.Decode(&event); err != nil {
+		log.Printf("failed to decode change event of %s: %s", sub.uri, err.Error())
+		return
+	}
+
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+
+	sub.events = append(sub.events, redaction.redactEvent(event))
+	if len(sub.events) > maxSubscriptionEvents {
+		sub.events = sub.events[len(sub.events)-maxSubscriptionEvents:]
+	}
+}
+
+// follow reads the change stream of the subscription until it is cancelled,
+// notifying the subscribed sessions once per batch of events. Interrupted
+// streams are reopened from the last resume token.
+func (r *MongoDBCollectionResources) follow(ctx context.Context, sub *changeSubscription, stream *mongo.ChangeStream) {
+	backoff := time.Second
+	for {
+		for stream.Next(ctx) {
+			backoff = time.Second
+			sub.record(stream, r.tool.redaction)
+			for stream.RemainingBatchLength() > 0 && stream.Next(ctx) {
+				sub.record(stream, r.tool.redaction)
+			}
+
+			if err := r.tool.resumeTokens.set(sub.key, stream.ResumeToken()); err != nil {
+				log.Printf("failed to store resume token of %s: %s", sub.uri, err.Error())
+			}
+			if sub.kind == "schema" {
+				r.tool.schemaCache.delete(sub.collection.Database().Name() + "." + sub.collection.Name())
+			}
+			if err := r.server.ResourceUpdated(ctx, &mcp.ResourceUpdatedNotificationParams{URI: sub.uri}); err != nil {
+				log.Printf("failed to notify the update of %s: %s", sub.uri, err.Error())
+			}
+		}
+
+		err := stream.Err()
+		stream.Close(context.WithoutCancel(ctx))
+		if ctx.Err() != nil {
+			return
+		}
+
+		// Streams closed by an invalidate event are reopened right away,
+		// failed streams after a growing delay.
+		for {
+			if err != nil {
+				log.Printf("change stream of %s interrupted, retrying in %s: %s", sub.uri, backoff, err.Error())
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(backoff):
+				}
+				backoff = min(backoff*2, maxSubscriptionBackoff)
+			}
+
+			stream, err = r.openChangeStream(ctx, sub)
+			if err == nil {
+				break
+			}
+			if ctx.Err() != nil {
+				return
+			}
+		}
+	}
+}
+
+// subscriptionKey returns the key of the subscription of the session to the
+// URI, the sessions with a scope of their own do not share change streams.
+func (r *MongoDBCollectionResources) subscriptionKey(session *mcp.ServerSession, uri string) (string, error) {
+	database, collection, kind, err := parseCollectionURI(uri)
+	if err != nil {
+		return "", err
+	}
+	if kind == "indexes" {
+		return uri, nil
+	}
+
+	scopeKey, err := r.tool.scope.key(session, database+"."+collection)
+	if err != nil {
+		return "", err
+	}
+	if scopeKey == "" {
+		return uri, nil
+	}
+	return uri + " " + scopeKey, nil
+}
+
+// Subscribe starts a change stream for the resource, or joins the stream of
+// an existing subscription to the same URI.
+func (r *MongoDBCollectionResources) Subscribe(ctx context.Context, req *mcp.SubscribeRequest) error {
+	uri := req.Params.URI
+	database, collection, kind, err := parseCollectionURI(uri)
+	if err != nil {
+		return err
+	}
+	switch kind {
+	case "schema", "indexes", "stats", "changes":
+	default:
+		return mcp.ResourceNotFoundError(uri)
+	}
+
+	pipeline, err := changePipeline(uri, kind, r.tool.filters)
+	if err != nil {
+		return err
+	}
+	if err := r.tool.redaction.checkEventPipeline(database, collection, pipeline); err != nil {
+		return err
+	}
+
+	key, err := r.subscriptionKey(req.Session, uri)
+	if err != nil {
+		return err
+	}
+	// The index events carry no documents, they are not scoped.
+	var scope bson.M
+	if kind != "indexes" {
+		scope, err = r.tool.scope.resolve(req.Session, database+"."+collection)
+		if err != nil {
+			return err
+		}
+	}
+	if scope != nil {
+		pipeline = append([]bson.M{changeStreamMatch(scope)}, pipeline...)
+	}
+
+	r.mu.Lock()
+	sub, ok := r.subscriptions[key]
+	if ok {
+		r.join(sub, req.Session)
+		r.mu.Unlock()
+		return nil
+	}
+	r.mu.Unlock()
+
+	DB, err := r.tool.Database(&database)
+	if err != nil {
+		return err
+	}
+	sub = &changeSubscription{
+		uri:        uri,
+		key:        key,
+		scoped:     scope != nil,
+		kind:       kind,
+		collection: DB.Collection(collection),
+		pipeline:   pipeline,
+		sessions:   map[*mcp.ServerSession]bool{},
+	}
+	// The stream is opened without holding the lock, so that the network
+	// round trips do not block the other subscriptions.
+	stream, err := r.openChangeStream(ctx, sub)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Another request may have subscribed to the same key in the meantime,
+	// its stream is kept and this one closed.
+	if existing, ok := r.subscriptions[key]; ok {
+		stream.Close(context.WithoutCancel(ctx))
+		r.join(existing, req.Session)
+		return nil
+	}
+
+	// The stream outlives the subscribe request.
+	var streamCtx context.Context
+	streamCtx, sub.cancel = context.WithCancel(context.WithoutCancel(ctx))
+	r.subscriptions[key] = sub
+	go r.follow(streamCtx, sub, stream)
+	r.join(sub, req.Session)
+
+	return nil
+}
+
+// join adds the session to the subscription and removes its subscriptions
+// once it ends. The caller holds r.mu.
+func (r *MongoDBCollectionResources) join(sub *changeSubscription, session *mcp.ServerSession) {
+	sub.sessions[session] = true
+
+	if !r.sessions[session] {
+		r.sessions[session] = true
+		go r.teardown(session)
+	}
+}
+
+// unsubscribe removes the session from the subscription of the key and stops
+// the change stream when no session is left. The caller holds r.mu.
+func (r *MongoDBCollectionResources) unsubscribe(key string, session *mcp.ServerSession) {
+	sub, ok := r.subscriptions[key]
+	if !ok {
+		return
+	}
+
+	delete(sub.sessions, session)
+	if len(sub.sessions) == 0 {
+		sub.cancel()
+		delete(r.subscriptions, key)
+	}
+}
+
+func (r *MongoDBCollectionResources) Unsubscribe(ctx context.Context, req *mcp.UnsubscribeRequest) error {
+	key, err := r.subscriptionKey(req.Session, req.Params.URI)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.unsubscribe(key, req.Session)
+
+	return nil
+}
+
+// teardown waits for the session to end and removes its subscriptions.
+func (r *MongoDBCollectionResources) teardown(session *mcp.ServerSession) {
+	session.Wait()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for key := range r.subscriptions {
+		r.unsubscribe(key, session)
+	}
+	delete(r.sessions, session)
+}
+
+func (r *MongoDBCollectionResources) readChanges(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	uri := req.Params.URI
+	key, err := r.subscriptionKey(req.Session, uri)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := MongoDBCollectionChanges{
+		Events: []bson.M{},
+	}
+
+	r.mu.Lock()
+	sub, ok := r.subscriptions[key]
+	r.mu.Unlock()
+	if ok {
+		changes.Subscribed = true
+		sub.mu.Lock()
+		changes.Events = append(changes.Events, sub.events...)
+		sub.mu.Unlock()
+	}
+
+	if token, ok := r.tool.resumeTokens.get(key); ok {
+		if err := bson.Unmarshal(token, &changes.ResumeToken); err != nil {
+			return nil, err
+		}
+	}
+
+	return r.result(uri, changes)
+}
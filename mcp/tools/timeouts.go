@@ -0,0 +1,58 @@
+package tools
+
+import (
+	"context"
+	"time"
+)
+
+// The categories of operations with their own default timeout.
+const (
+	operationRead      = "read"
+	operationWrite     = "write"
+	operationAggregate = "aggregate"
+	operationAdmin     = "admin"
+)
+
+// queryTimeouts bounds the operations of the tools by a deadline, the driver
+// sends the remaining time to the server as maxTimeMS so that runaway queries
+// are stopped on the server too.
+type queryTimeouts struct {
+	defaults map[string]time.Duration
+	max      time.Duration
+}
+
+func newQueryTimeouts() *queryTimeouts {
+	return &queryTimeouts{
+		defaults: map[string]time.Duration{
+			operationRead:      30 * time.Second,
+			operationWrite:     30 * time.Second,
+			operationAggregate: 60 * time.Second,
+			operationAdmin:     5 * time.Minute,
+		},
+		max: 5 * time.Minute,
+	}
+}
+
+// limit returns the time limit of an operation of the category, the
+// max_time_ms input when given, capped by the server maximum. A zero limit
+// means no limit.
+func (q *queryTimeouts) limit(category string, maxTimeMillis *int64) time.Duration {
+	limit := q.defaults[category]
+	if maxTimeMillis != nil && *maxTimeMillis > 0 {
+		limit = time.Duration(*maxTimeMillis) * time.Millisecond
+	}
+	if q.max > 0 && (limit <= 0 || limit > q.max) {
+		limit = q.max
+	}
+	return limit
+}
+
+// withTimeout bounds the context by the time limit of an operation of the
+// category.
+func (q *queryTimeouts) withTimeout(ctx context.Context, category string, maxTimeMillis *int64) (context.Context, context.CancelFunc) {
+	limit := q.limit(category, maxTimeMillis)
+	if limit <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, limit)
+}
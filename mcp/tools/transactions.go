@@ -0,0 +1,181 @@
+package tools
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// transaction is a driver session with an open transaction, owned by the MCP
+// session that began it.
+type transaction struct {
+	// mu serializes the operations of the transaction, as a driver session
+	// cannot be used concurrently.
+	mu      sync.Mutex
+	session *mongo.Session
+	owner   *mcp.ServerSession
+	timer   *time.Timer
+	started time.Time
+}
+
+// transactionManager keeps the open transactions by id and aborts them when
+// they time out or when their MCP session ends.
+type transactionManager struct {
+	mu           sync.Mutex
+	timeout      time.Duration
+	transactions map[string]*transaction
+	sessions     map[*mcp.ServerSession]bool
+}
+
+func newTransactionManager(timeout time.Duration) *transactionManager {
+	return &transactionManager{
+		timeout:      timeout,
+		transactions: map[string]*transaction{},
+		sessions:     map[*mcp.ServerSession]bool{},
+	}
+}
+
+func newTransactionID() (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(id), nil
+}
+
+// begin starts a transaction on a new driver session of the client and
+// returns its id.
+func (m *transactionManager) begin(ctx context.Context, client *mongo.Client, owner *mcp.ServerSession) (string, time.Time, error) {
+	id, err := newTransactionID()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	session, err := client.StartSession()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	if err := session.StartTransaction(); err != nil {
+		session.EndSession(ctx)
+		return "", time.Time{}, err
+	}
+
+	tx := &transaction{
+		session: session,
+		owner:   owner,
+		started: time.Now(),
+	}
+	expires := tx.started.Add(m.timeout)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.transactions[id] = tx
+	tx.timer = time.AfterFunc(m.timeout, func() {
+		m.abort(context.Background(), id, nil)
+	})
+	if owner != nil && !m.sessions[owner] {
+		m.sessions[owner] = true
+		go m.teardown(owner)
+	}
+
+	return id, expires, nil
+}
+
+// take removes the transaction of the owner from the open transactions.
+func (m *transactionManager) take(id string, owner *mcp.ServerSession) (*transaction, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	tx, ok := m.transactions[id]
+	if !ok || (owner != nil && tx.owner != owner) {
+		return nil, fmt.Errorf("Transaction %s does not exist, it may have been committed, aborted or timed out", id)
+	}
+	delete(m.transactions, id)
+	tx.timer.Stop()
+
+	return tx, nil
+}
+
+// commit commits the transaction and ends its session.
+func (m *transactionManager) commit(ctx context.Context, id string, owner *mcp.ServerSession) error {
+	tx, err := m.take(id, owner)
+	if err != nil {
+		return err
+	}
+
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	defer tx.session.EndSession(context.WithoutCancel(ctx))
+
+	return tx.session.CommitTransaction(ctx)
+}
+
+// abort aborts the transaction and ends its session. A nil owner aborts the
+// transaction of any session.
+func (m *transactionManager) abort(ctx context.Context, id string, owner *mcp.ServerSession) error {
+	tx, err := m.take(id, owner)
+	if err != nil {
+		return err
+	}
+
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	defer tx.session.EndSession(context.WithoutCancel(ctx))
+
+	return tx.session.AbortTransaction(ctx)
+}
+
+// join returns a context running the operations in the transaction with the
+// given id, and a function to call once the operation is done. Without an id
+// the context is returned as is.
+func (m *transactionManager) join(ctx context.Context, owner *mcp.ServerSession, id *string) (context.Context, func(), error) {
+	if id == nil || *id == "" {
+		return ctx, func() {}, nil
+	}
+
+	m.mu.Lock()
+	tx, ok := m.transactions[*id]
+	m.mu.Unlock()
+	if !ok || (owner != nil && tx.owner != owner) {
+		return nil, nil, fmt.Errorf("Transaction %s does not exist, it may have been committed, aborted or timed out", *id)
+	}
+
+	tx.mu.Lock()
+	// The transaction may have ended while waiting for the previous
+	// operation.
+	m.mu.Lock()
+	current := m.transactions[*id]
+	m.mu.Unlock()
+	if current != tx {
+		tx.mu.Unlock()
+		return nil, nil, fmt.Errorf("Transaction %s does not exist, it may have been committed, aborted or timed out", *id)
+	}
+
+	return mongo.NewSessionContext(ctx, tx.session), tx.mu.Unlock, nil
+}
+
+// teardown waits for the session to end and aborts its open transactions.
+func (m *transactionManager) teardown(session *mcp.ServerSession) {
+	session.Wait()
+
+	m.mu.Lock()
+	ids := []string{}
+	for id, tx := range m.transactions {
+		if tx.owner == session {
+			ids = append(ids, id)
+		}
+	}
+	delete(m.sessions, session)
+	m.mu.Unlock()
+
+	for _, id := range ids {
+		m.abort(context.Background(), id, session)
+	}
+}
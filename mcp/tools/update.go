@@ -0,0 +1,46 @@
+package tools
+
+import (
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// updateDocument validates the update of the update tools, either a document
+// of update operators or an aggregation pipeline given as an array of stages.
+func updateDocument(update any) (any, error) {
+	switch value := update.(type) {
+	case map[string]any:
+		return updateDocument(bson.M(value))
+	case bson.M:
+		if len(value) == 0 {
+			return nil, fmt.Errorf("The update must not be empty")
+		}
+		return value, nil
+	case []any:
+		if len(value) == 0 {
+			return nil, fmt.Errorf("The update pipeline must have at least one stage")
+		}
+		pipeline := make([]bson.M, 0, len(value))
+		for i, stage := range value {
+			document, ok := stage.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("Stage %d of the update pipeline is not a document", i)
+			}
+			pipeline = append(pipeline, bson.M(document))
+		}
+		return pipeline, nil
+	}
+
+	return nil, fmt.Errorf("The update must be a document of update operators or an array of pipeline stages")
+}
+
+// arrayFilters converts the array filters of the update tools to the type
+// expected by the driver.
+func arrayFilters(filters []bson.M) []any {
+	converted := make([]any, 0, len(filters))
+	for _, filter := range filters {
+		converted = append(converted, filter)
+	}
+	return converted
+}
@@ -10,11 +10,18 @@ import (
 )
 
 type MongoDBUpdateOneToolInput struct {
-	DatabaseName   *string `json:"database_name,omitempty" jsonschema:"Optional name of the database to find the document in"`
-	CollectionName string  `json:"collection_name" jsonschema:"Name of the collection to find the document in"`
-	Filter         bson.M  `json:"filter" jsonschema:"The filter to find the document with"`
-	Update         bson.M  `json:"update" jsonschema:"The update to apply to the document"`
-	Upsert         *bool   `json:"upsert,omitempty" jsonschema:"Optional whether to insert the document if it doesn't exist, defaults to false"`
+	DatabaseName        *string                `json:"database_name,omitempty" jsonschema:"Optional name of the database to find the document in"`
+	CollectionName      string                 `json:"collection_name" jsonschema:"Name of the collection to find the document in"`
+	Filter              bson.M                 `json:"filter" jsonschema:"The filter to find the document with"`
+	Update              any                    `json:"update" jsonschema:"The update to apply, either a document of update operators or an aggregation pipeline given as an array of stages"`
+	Upsert              *bool                  `json:"upsert,omitempty" jsonschema:"Optional whether to insert the document if it doesn't exist, defaults to false"`
+	ArrayFilters        []bson.M               `json:"array_filters,omitempty" jsonschema:"Optional filters selecting the array elements updated through the $[<identifier>] positional operator"`
+	Hint                *string                `json:"hint,omitempty" jsonschema:"Optional name of the index to use"`
+	Collation           *MongoDBCollationInput `json:"collation,omitempty" jsonschema:"Optional collation used to match the filter"`
+	Let                 bson.M                 `json:"let,omitempty" jsonschema:"Optional variables accessible as $$<name> in the filter and the update"`
+	AllowCollectionScan *bool                  `json:"allow_collection_scan,omitempty" jsonschema:"Optional confirmation to run the operation even if the query cost guard detects a collection scan, only honoured when the server asks for confirmation"`
+	TransactionID       *string                `json:"transaction_id,omitempty" jsonschema:"Optional id of a transaction returned by the Begin Transaction tool to run the operation in"`
+	MaxTimeMillis       *int64                 `json:"max_time_ms,omitempty" jsonschema:"Optional time limit of the operation in milliseconds, defaults to the server timeout of the operation and is capped by the server maximum"`
 }
 
 type MongoDBUpdateOneToolOutput struct {
@@ -53,6 +60,27 @@ func (t *NewMongoDBUpdateOneTool) toolCall(
 		Result: nil,
 	}
 
+	done, err := t.tool.quotas.acquire(req, operationWrite)
+	if err != nil {
+		return nil, defResponse, err
+	}
+	defer done()
+
+	ctx, cancel := t.tool.timeouts.withTimeout(ctx, operationWrite, input.MaxTimeMillis)
+	defer cancel()
+
+	update, err := updateDocument(input.Update)
+	if err != nil {
+		return nil, defResponse, err
+	}
+	if err := t.tool.pipelines.checkUpdate(update, "update"); err != nil {
+		return nil, defResponse, err
+	}
+
+	if err := t.tool.filters.check(input.Filter, "filter"); err != nil {
+		return nil, defResponse, err
+	}
+
 	DB, err := t.tool.Database(input.DatabaseName)
 	if err != nil {
 		return nil, defResponse, err
@@ -60,12 +88,53 @@ func (t *NewMongoDBUpdateOneTool) toolCall(
 
 	collection := DB.Collection(input.CollectionName)
 
+	if err := t.tool.redaction.checkQuery(collectionNamespace(collection), input.Filter, nil); err != nil {
+		return nil, defResponse, err
+	}
+
+	scope, err := t.tool.scope.resolve(req.Session, collectionNamespace(collection))
+	if err != nil {
+		return nil, defResponse, err
+	}
+	filter := scopedFilter(scope, input.Filter)
+	if err := scopeUpdate(scope, update, "update"); err != nil {
+		return nil, defResponse, err
+	}
+	if input.Upsert != nil && *input.Upsert {
+		if err := scopeUpsert(scope, input.Filter, "filter"); err != nil {
+			return nil, defResponse, err
+		}
+	}
+
+	query := explainQuery{operation: "find", filter: filter, limit: 1}
+	if err := t.tool.guard.check(ctx, collection, query, input.AllowCollectionScan); err != nil {
+		return nil, defResponse, err
+	}
+
+	ctx, release, err := t.tool.transactions.join(ctx, req.Session, input.TransactionID)
+	if err != nil {
+		return nil, defResponse, err
+	}
+	defer release()
+
 	opts := options.UpdateOne()
 	if input.Upsert != nil && *input.Upsert {
 		opts.SetUpsert(*input.Upsert)
 	}
+	if len(input.ArrayFilters) > 0 {
+		opts.SetArrayFilters(arrayFilters(input.ArrayFilters))
+	}
+	if input.Hint != nil && *input.Hint != "" {
+		opts.SetHint(*input.Hint)
+	}
+	if input.Collation != nil {
+		opts.SetCollation(input.Collation.options())
+	}
+	if len(input.Let) > 0 {
+		opts.SetLet(input.Let)
+	}
 
-	res, err := collection.UpdateOne(ctx, input.Filter, input.Update, opts)
+	res, err := collection.UpdateOne(ctx, filter, update, opts)
 	if err != nil {
 		return nil, defResponse, err
 	}
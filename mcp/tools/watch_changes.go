@@ -0,0 +1,195 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+const (
+	defaultWatchDuration  = 5 * time.Second
+	maxWatchDuration      = 60 * time.Second
+	defaultWatchMaxEvents = 100
+	maxWatchMaxEvents     = 1000
+	// watchPollInterval bounds each wait for new events on the server, so
+	// that the collection window is honoured without cancelling a getMore.
+	watchPollInterval = time.Second
+)
+
+type MongoDBWatchChangesToolInput struct {
+	DatabaseName   *string  `json:"database_name,omitempty" jsonschema:"Optional name of the database to watch"`
+	CollectionName *string  `json:"collection_name,omitempty" jsonschema:"Optional name of the collection to watch, defaults to all collections of the database"`
+	Pipeline       []bson.M `json:"pipeline,omitempty" jsonschema:"Optional pipeline applied to the change events, such as a $match on operationType or a $project"`
+	ResumeToken    bson.M   `json:"resume_token,omitempty" jsonschema:"Optional resume token returned by a previous call, events are returned from right after it"`
+	FullDocument   *string  `json:"full_document,omitempty" jsonschema:"Optional full document mode of update events, one of default, updateLookup, whenAvailable or required"`
+	DurationMillis *int64   `json:"duration_ms,omitempty" jsonschema:"Optional time to collect events for in milliseconds, defaults to 5000 and is capped at 60000"`
+	MaxEvents      *int     `json:"max_events,omitempty" jsonschema:"Optional maximum number of events to collect, defaults to 100 and is capped at 1000"`
+}
+
+type MongoDBWatchChangesToolOutput struct {
+	Events      []bson.M `json:"events" jsonschema:"The change events in the order they happened, the _id of each event is its own resume token"`
+	ResumeToken bson.M   `json:"resume_token" jsonschema:"The resume token to pass to the next call to continue right after the last returned event"`
+	HasMore     bool     `json:"has_more" jsonschema:"Whether the collection stopped at max_events, more events may be waiting"`
+	Invalidated bool     `json:"invalidated" jsonschema:"Whether the stream was invalidated, for example because the watched collection was dropped or renamed"`
+}
+
+type NewMongoDBWatchChangesTool struct {
+	tool *Tool
+}
+
+func (t *Tool) NewMongoDBWatchChangesTool() *NewMongoDBWatchChangesTool {
+	return &NewMongoDBWatchChangesTool{
+		tool: t,
+	}
+}
+
+func (t *NewMongoDBWatchChangesTool) name() string {
+	return "[MongoDB] Watch Changes Tool"
+}
+
+func (t *NewMongoDBWatchChangesTool) description() string {
+	return "# Watch the changes in MongoDB.\n\n" +
+		"This tool opens a change stream on a MongoDB collection, or on all collections of a database, and collects " +
+		"the change events for a bounded duration or number of events.\n\n" +
+		"It returns the events with a resume token, pass the token to the next call to continue exactly where this " +
+		"call stopped without missing or repeating events. Change streams require a replica set or a sharded cluster.\n\n"
+}
+
+func (t *NewMongoDBWatchChangesTool) toolCall(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	input MongoDBWatchChangesToolInput,
+) (
+	*mcp.CallToolResult,
+	MongoDBWatchChangesToolOutput,
+	error,
+) {
+	defResponse := MongoDBWatchChangesToolOutput{
+		Events: []bson.M{},
+	}
+
+	done, err := t.tool.quotas.acquire(req, operationRead)
+	if err != nil {
+		return nil, defResponse, err
+	}
+	defer done()
+
+	duration := defaultWatchDuration
+	if input.DurationMillis != nil && *input.DurationMillis > 0 {
+		duration = min(time.Duration(*input.DurationMillis)*time.Millisecond, maxWatchDuration)
+	}
+	maxEvents := defaultWatchMaxEvents
+	if input.MaxEvents != nil && *input.MaxEvents > 0 {
+		maxEvents = min(*input.MaxEvents, maxWatchMaxEvents)
+	}
+
+	DB, err := t.tool.Database(input.DatabaseName)
+	if err != nil {
+		return nil, defResponse, err
+	}
+
+	opts := options.ChangeStream().SetMaxAwaitTime(min(duration, watchPollInterval))
+	if input.FullDocument != nil && *input.FullDocument != "" {
+		switch fullDocument := options.FullDocument(*input.FullDocument); fullDocument {
+		case options.Default, options.UpdateLookup, options.WhenAvailable, options.Required:
+			opts.SetFullDocument(fullDocument)
+		default:
+			return nil, defResponse, fmt.Errorf("Invalid full_document %q, use default, updateLookup, whenAvailable or required", *input.FullDocument)
+		}
+	}
+	if len(input.ResumeToken) > 0 {
+		// Unlike resumeAfter, startAfter also resumes after an invalidate
+		// event.
+		opts.SetStartAfter(input.ResumeToken)
+	}
+
+	var scope bson.M
+	if input.CollectionName != nil && *input.CollectionName != "" {
+		scope, err = t.tool.scope.resolve(req.Session, DB.Name()+"."+*input.CollectionName)
+	} else {
+		scope, err = t.tool.scope.resolveDatabase(req.Session, DB.Name())
+	}
+	if err != nil {
+		return nil, defResponse, err
+	}
+
+	pipeline := input.Pipeline
+	if pipeline == nil {
+		pipeline = []bson.M{}
+	}
+	collection := ""
+	if input.CollectionName != nil {
+		collection = *input.CollectionName
+	}
+	if err := t.tool.filters.checkPipeline(pipeline, "pipeline"); err != nil {
+		return nil, defResponse, err
+	}
+	if err := t.tool.redaction.checkEventPipeline(DB.Name(), collection, pipeline); err != nil {
+		return nil, defResponse, err
+	}
+	if scope != nil {
+		// The scope is matched against the full document, which update
+		// events only carry when it is looked up.
+		pipeline = append([]bson.M{changeStreamMatch(scope)}, pipeline...)
+		if input.FullDocument == nil || *input.FullDocument == "" || *input.FullDocument == string(options.Default) {
+			opts.SetFullDocument(options.UpdateLookup)
+		}
+	}
+
+	var stream *mongo.ChangeStream
+	if input.CollectionName != nil && *input.CollectionName != "" {
+		stream, err = DB.Collection(*input.CollectionName).Watch(ctx, pipeline, opts)
+	} else {
+		stream, err = DB.Watch(ctx, pipeline, opts)
+	}
+	if err != nil {
+		return nil, defResponse, err
+	}
+	defer stream.Close(context.WithoutCancel(ctx))
+
+	output := defResponse
+	deadline := time.Now().Add(duration)
+	for len(output.Events) < maxEvents && time.Now().Before(deadline) {
+		if !stream.TryNext(ctx) {
+			if err := stream.Err(); err != nil {
+				return nil, defResponse, err
+			}
+			if stream.ID() == 0 {
+				// The server closed the stream after an invalidate event.
+				break
+			}
+			continue
+		}
+
+		var event bson.M
+		if err := stream.Decode(&event); err != nil {
+			return nil, defResponse, err
+		}
+		output.Events = append(output.Events, t.tool.redaction.redactEvent(event))
+		if event["operationType"] == "invalidate" {
+			output.Invalidated = true
+			break
+		}
+	}
+	output.HasMore = len(output.Events) >= maxEvents
+
+	if token := stream.ResumeToken(); token != nil {
+		if err := bson.Unmarshal(token, &output.ResumeToken); err != nil {
+			return nil, defResponse, err
+		}
+	}
+
+	return nil, output, nil
+}
+
+func (t *NewMongoDBWatchChangesTool) AttachTool(server *mcp.Server) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        t.name(),
+		Description: t.description(),
+	}, t.toolCall)
+}